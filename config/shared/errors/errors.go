@@ -33,10 +33,49 @@ var (
 	ErrDeprecated         = errors.New("config format deprecated")
 	ErrCompressionInvalid = errors.New("invalid compression method")
 
+	// Resource encryption errors
+	ErrEncryptionFormatInvalid  = errors.New("invalid encryption format")
+	ErrEncryptionKeyRequired    = errors.New("one of keyFile, sealedKeyFile, or keyringKey is required")
+	ErrEncryptionKeyConflict    = errors.New("only one of keyFile, sealedKeyFile, or keyringKey can be specified")
+	ErrEncryptionAndNilSource   = errors.New("source must be specified if encryption is specified")
+	ErrEncryptionAndCompression = errors.New("compression cannot be used with encryption")
+	ErrEncryptionNotSupported   = errors.New("encryption is not supported for this resource")
+
+	// Retry policy errors
+	ErrRetryMaxAttemptsInvalid = errors.New("maxAttempts must be at least 1")
+	ErrRetryBackoffInvalid     = errors.New("backoff must not be negative")
+	ErrRetryMaxBackoffTooSmall = errors.New("maxBackoff must not be less than initialBackoff")
+	ErrRetryStatusCodeInvalid  = errors.New("invalid HTTP status code")
+
+	// Network limit errors
+	ErrMaxBandwidthInvalid         = errors.New("maxBandwidth must be at least 1")
+	ErrMaxConcurrentFetchesInvalid = errors.New("maxConcurrentFetches must be at least 1")
+	ErrPeerPortInvalid             = errors.New("port must be between 1 and 65535")
+	ErrPeerAddressInvalid          = errors.New("peer address must be a host, optionally followed by a port")
+
+	// Resource authentication errors
+	ErrAuthTypeRequired           = errors.New("auth type is required")
+	ErrAuthTypeInvalid            = errors.New("invalid auth type")
+	ErrAuthTokenSourceRequired    = errors.New("tokenSource is required for bearer auth")
+	ErrAuthAwsServiceRequired     = errors.New("awsService is required for aws-sigv4 auth")
+	ErrAuthAudienceRequired       = errors.New("audience is required for azure-managed-identity auth")
+	ErrAuthFieldNotApplicable     = errors.New("field is not applicable to this auth type")
+	ErrUnsupportedSchemeForAuth   = errors.New("cannot use auth with this source scheme")
+	ErrAuthAndAuthorizationHeader = errors.New("cannot use auth with an Authorization HTTP header")
+
+	// Vault errors
+	ErrVaultAuthMethodRequired = errors.New("authMethod is required")
+	ErrVaultAuthMethodInvalid  = errors.New("invalid Vault auth method")
+	ErrVaultRoleIDRequired     = errors.New("roleId is required for approle auth")
+	ErrVaultRoleRequired       = errors.New("role is required for jwt auth")
+
 	// Storage section errors
 	ErrFileUsedSymlink                  = errors.New("file path includes link in config")
 	ErrDirectoryUsedSymlink             = errors.New("directory path includes link in config")
 	ErrLinkUsedSymlink                  = errors.New("link path includes link in config")
+	ErrArchiveUsedSymlink               = errors.New("archive path includes link in config")
+	ErrArchiveSourceRequired            = errors.New("archive contents source is required")
+	ErrArchiveFormatInvalid             = errors.New("invalid archive format")
 	ErrLinkTargetRequired               = errors.New("link target is required")
 	ErrHardLinkToDirectory              = errors.New("hard link target is a directory")
 	ErrHardLinkSpecifiesOwner           = errors.New("user/group ignored for hard link")
@@ -45,7 +84,11 @@ var (
 	ErrPartitionsOverlap                = errors.New("partitions overlap")
 	ErrPartitionsMisaligned             = errors.New("partitions misaligned")
 	ErrOverwriteAndNilSource            = errors.New("overwrite must be false if source is unspecified")
+	ErrKeepUnchangedWithoutOverwrite    = errors.New("keepUnchanged requires overwrite to be true")
+	ErrKeepUnchangedWithoutHash         = errors.New("keepUnchanged requires contents to have a verification hash")
 	ErrVerificationAndNilSource         = errors.New("source must be specified if verification is specified")
+	ErrPasswordHashSourceConflict       = errors.New("passwordHash and passwordHashSource cannot both be specified")
+	ErrSourcesAndNilSource              = errors.New("source must be specified if sources is specified")
 	ErrFilesystemInvalidFormat          = errors.New("invalid filesystem format")
 	ErrLabelNeedsFormat                 = errors.New("filesystem must specify format if label is specified")
 	ErrFormatNilWithOthers              = errors.New("format cannot be empty when path, label, uuid, wipeFilesystem, options, or mountOptions is specified")
@@ -89,6 +132,7 @@ var (
 	ErrDuplicateLabels                  = errors.New("cannot use the same partition label twice")
 	ErrInvalidProxy                     = errors.New("proxies must be http(s)")
 	ErrInsecureProxy                    = errors.New("insecure plaintext HTTP proxy specified for HTTPS resources")
+	ErrProxyCredentialsWithoutProxy     = errors.New("proxy credentials require httpProxy, httpsProxy, autoConfig, or autoDiscover")
 	ErrPathConflictsSystemd             = errors.New("path conflicts with systemd unit or dropin")
 	ErrCexWithClevis                    = errors.New("cannot use cex with clevis")
 	ErrCexWithKeyFile                   = errors.New("cannot use key file with cex")
@@ -106,15 +150,30 @@ var (
 	ErrInvalidHTTPHeader               = errors.New("unable to parse HTTP header")
 	ErrEmptyHTTPHeaderName             = errors.New("HTTP header name can't be empty")
 	ErrUnsupportedSchemeForHTTPHeaders = errors.New("cannot use HTTP headers with this source scheme")
+	ErrClientCertificateKeyPair        = errors.New("clientCertificate and clientKey must be specified together")
+	ErrPublicKeyPinInvalid             = errors.New("public key pin must be \"sha256/\" followed by a base64-encoded SHA-256 hash")
+	ErrTLSVersionInvalid               = errors.New("TLS version must be 1.2 or 1.3")
+	ErrCipherSuiteInvalid              = errors.New("unknown or insecure TLS cipher suite")
 	ErrHashMalformed                   = errors.New("malformed hash specifier")
 	ErrHashWrongSize                   = errors.New("incorrect size for hash sum")
 	ErrHashUnrecognized                = errors.New("unrecognized hash function")
+	ErrSignatureNotSupported           = errors.New("signatures are only supported on config references")
 	ErrEngineConfiguration             = errors.New("engine incorrectly configured")
 
 	// AWS S3 specific errors
 	ErrInvalidS3ARN             = errors.New("invalid S3 ARN format")
 	ErrInvalidS3ObjectVersionId = errors.New("invalid S3 object VersionId")
 
+	// Local source specific errors
+	ErrInvalidFileURL  = errors.New("invalid file URL; must be file:///absolute/path")
+	ErrInvalidBlockURL = errors.New("invalid block URL; must be block://LABEL/path")
+
+	// OCI specific errors
+	ErrInvalidOCIReference = errors.New("invalid OCI reference; must be oci://registry/repository[:tag|@digest]")
+
+	// Vault specific errors
+	ErrInvalidVaultURL = errors.New("invalid Vault URL; must be vault://address/path[?field=name][&version=n] or vault://address/mount/decrypt/key?ciphertext=value")
+
 	// Obsolete errors, left here for ABI compatibility
 	ErrFilePermissionsUnset      = errors.New("permissions unset, defaulting to 0644")
 	ErrDirectoryPermissionsUnset = errors.New("permissions unset, defaulting to 0755")
@@ -0,0 +1,80 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+)
+
+var (
+	ociRepositoryRe = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	ociTagRe        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	ociDigestRe     = regexp.MustCompile(`^(?:sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`)
+)
+
+// OCIReference is an artifact in an OCI registry, parsed from a URL of the
+// form oci://registry/repository[:tag|@digest][?file=name].
+type OCIReference struct {
+	// Registry is the host and optional port of the registry.
+	Registry string
+	// Repository is the name of the repository within the registry.
+	Repository string
+	// Reference is the tag or digest of the manifest, defaulting to
+	// "latest".
+	Reference string
+	// File, if set, is the title of the layer to fetch. Otherwise the
+	// artifact must have a single layer.
+	File string
+}
+
+// IsDigest returns whether the reference is a digest rather than a tag.
+func (r OCIReference) IsDigest() bool {
+	return strings.Contains(r.Reference, ":")
+}
+
+// ParseOCIReference parses an oci URL.
+func ParseOCIReference(u url.URL) (OCIReference, error) {
+	if u.Scheme != "oci" || u.Host == "" || u.Opaque != "" {
+		return OCIReference{}, errors.ErrInvalidOCIReference
+	}
+	ref := OCIReference{
+		Registry:  u.Host,
+		Reference: "latest",
+		File:      u.Query().Get("file"),
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		ref.Reference = name[i+1:]
+		name = name[:i]
+		if !ociDigestRe.MatchString(ref.Reference) {
+			return OCIReference{}, errors.ErrInvalidOCIReference
+		}
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Reference = name[i+1:]
+		name = name[:i]
+		if !ociTagRe.MatchString(ref.Reference) {
+			return OCIReference{}, errors.ErrInvalidOCIReference
+		}
+	}
+	if !ociRepositoryRe.MatchString(name) {
+		return OCIReference{}, errors.ErrInvalidOCIReference
+	}
+	ref.Repository = name
+	return ref, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+)
+
+// DefaultPeerPort is the port on which peers share resources if
+// ignition.network.peers.port isn't specified.
+const DefaultPeerPort = 4790
+
+// ParsePeerAddress parses the address of a peer, which is a host optionally
+// followed by a port, and returns it in host:port form, using port if the
+// address doesn't specify one.
+func ParsePeerAddress(address string, port int) (string, error) {
+	host := address
+	if h, p, err := net.SplitHostPort(address); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return "", errors.ErrPeerAddressInvalid
+		}
+		host, port = h, n
+	} else if strings.Contains(address, ":") && net.ParseIP(address) == nil {
+		// neither host:port nor a bare IPv6 address
+		return "", errors.ErrPeerAddressInvalid
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@[] ") {
+		return "", errors.ErrPeerAddressInvalid
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
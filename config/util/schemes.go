@@ -0,0 +1,132 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/vincent-petithory/dataurl"
+)
+
+// schemeValidators validate URLs with each source scheme: those built into
+// Ignition, and those registered by programs embedding it.
+var schemeValidators = map[string]func(url.URL) error{}
+
+func init() {
+	for scheme, validate := range map[string]func(url.URL) error{
+		"http":  validateAnyURL,
+		"https": validateAnyURL,
+		"tftp":  validateAnyURL,
+		"gs":    validateAnyURL,
+		"s3":    validateS3URL,
+		"arn":   validateARNURL,
+		"file":  validateFileURL,
+		"block": validateBlockURL,
+		"oci":   validateOCIURL,
+		"vault": validateVaultURL,
+		"data":  validateDataURL,
+	} {
+		RegisterScheme(scheme, validate)
+	}
+}
+
+// RegisterScheme makes URLs with the source scheme valid in configs, as long
+// as validate accepts them. It panics if the scheme is already registered,
+// including if it's built in.
+func RegisterScheme(scheme string, validate func(url.URL) error) {
+	if _, ok := schemeValidators[scheme]; ok {
+		panic(fmt.Sprintf("source scheme %q already registered", scheme))
+	}
+	schemeValidators[scheme] = validate
+}
+
+// LookupScheme returns the validator of a source scheme, and whether the
+// scheme is supported.
+func LookupScheme(scheme string) (func(url.URL) error, bool) {
+	validate, ok := schemeValidators[scheme]
+	return validate, ok
+}
+
+func validateAnyURL(u url.URL) error {
+	return nil
+}
+
+func validateS3VersionId(u url.URL) error {
+	if v, ok := u.Query()["versionId"]; ok {
+		if len(v) == 0 || v[0] == "" {
+			return errors.ErrInvalidS3ObjectVersionId
+		}
+	}
+	return nil
+}
+
+func validateS3URL(u url.URL) error {
+	return validateS3VersionId(u)
+}
+
+func validateARNURL(u url.URL) error {
+	fullURL := u.Scheme + ":" + u.Opaque
+	if !arn.IsARN(fullURL) {
+		return errors.ErrInvalidS3ARN
+	}
+	s3arn, err := arn.Parse(fullURL)
+	if err != nil {
+		return err
+	}
+	if s3arn.Service != "s3" {
+		return errors.ErrInvalidS3ARN
+	}
+	urlSplit := strings.Split(fullURL, "/")
+	if strings.HasPrefix(s3arn.Resource, "accesspoint/") && len(urlSplit) < 3 {
+		return errors.ErrInvalidS3ARN
+	} else if len(urlSplit) < 2 {
+		return errors.ErrInvalidS3ARN
+	}
+	return validateS3VersionId(u)
+}
+
+func validateFileURL(u url.URL) error {
+	if u.Opaque != "" || (u.Host != "" && u.Host != "localhost") || !strings.HasPrefix(u.Path, "/") {
+		return errors.ErrInvalidFileURL
+	}
+	return nil
+}
+
+func validateBlockURL(u url.URL) error {
+	if u.Opaque != "" || u.Host == "" || u.Host == "." || u.Host == ".." || strings.Trim(u.Path, "/") == "" {
+		return errors.ErrInvalidBlockURL
+	}
+	return nil
+}
+
+func validateOCIURL(u url.URL) error {
+	_, err := ParseOCIReference(u)
+	return err
+}
+
+func validateVaultURL(u url.URL) error {
+	_, err := ParseVaultURL(u)
+	return err
+}
+
+func validateDataURL(u url.URL) error {
+	_, err := dataurl.DecodeString(u.String())
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+)
+
+// ParsePublicKeyPin returns the SHA-256 hash of a subject public key info
+// from a pin of the form sha256/<base64>.
+func ParsePublicKeyPin(pin string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(pin, "sha256/")
+	if !ok {
+		return nil, errors.ErrPublicKeyPinInvalid
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, errors.ErrPublicKeyPinInvalid
+	}
+	return sum, nil
+}
+
+// ParseTLSVersion returns the crypto/tls constant for a TLS version of the
+// form "1.2".
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, errors.ErrTLSVersionInvalid
+	}
+}
+
+// ParseCipherSuite returns the ID of the named cipher suite. Only suites
+// without known security issues are accepted.
+func ParseCipherSuite(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, nil
+		}
+	}
+	return 0, errors.ErrCipherSuiteInvalid
+}
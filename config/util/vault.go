@@ -0,0 +1,73 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+)
+
+// VaultReference is a secret in HashiCorp Vault, parsed from a URL of the
+// form vault://address/path[?field=name][&version=n], or
+// vault://address/mount/decrypt/key?ciphertext=value for Transit
+// decryption.
+type VaultReference struct {
+	// Address is the host and optional port of the Vault server.
+	Address string
+	// Path is the API path of the secret, without the /v1/ prefix.
+	Path string
+	// Field, if set, is the key of the secret's data to fetch. Otherwise
+	// the secret must have a single key.
+	Field string
+	// Version, if set, is the version of a KV version 2 secret.
+	Version string
+	// Ciphertext, if set, is decrypted with the Transit key at Path.
+	Ciphertext string
+}
+
+// ParseVaultURL parses a vault URL.
+func ParseVaultURL(u url.URL) (VaultReference, error) {
+	if u.Scheme != "vault" || u.Host == "" || u.Opaque != "" || u.User != nil {
+		return VaultReference{}, errors.ErrInvalidVaultURL
+	}
+	query := u.Query()
+	ref := VaultReference{
+		Address: u.Host,
+		Path:    strings.Trim(u.Path, "/"),
+		Field:   query.Get("field"),
+		Version: query.Get("version"),
+		// base64 ciphertext may contain unescaped '+', which would
+		// otherwise be decoded as a space
+		Ciphertext: strings.ReplaceAll(query.Get("ciphertext"), " ", "+"),
+	}
+	if ref.Path == "" || strings.Contains("/"+ref.Path+"/", "/../") {
+		return VaultReference{}, errors.ErrInvalidVaultURL
+	}
+	if ref.Version != "" {
+		if v, err := strconv.Atoi(ref.Version); err != nil || v < 1 {
+			return VaultReference{}, errors.ErrInvalidVaultURL
+		}
+	}
+	if ref.Ciphertext != "" {
+		parts := strings.Split(ref.Path, "/")
+		if ref.Field != "" || ref.Version != "" || len(parts) < 3 || parts[len(parts)-2] != "decrypt" {
+			return VaultReference{}, errors.ErrInvalidVaultURL
+		}
+	}
+	return ref, nil
+}
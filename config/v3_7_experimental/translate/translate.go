@@ -20,16 +20,113 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 )
 
+// newTranslator returns a translator for the structs which are unchanged
+// apart from nested structs with new fields.
+func newTranslator() translate.Translator {
+	tr := translate.NewTranslator()
+	tr.AddCustomTranslator(translateResource)
+	return tr
+}
+
+func translateResource(old old_types.Resource) (ret types.Resource) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Compression, &ret.Compression)
+	tr.Translate(&old.HTTPHeaders, &ret.HTTPHeaders)
+	tr.Translate(&old.Source, &ret.Source)
+	ret.Verification = translateVerification(old.Verification)
+	return
+}
+
+func translateVerification(old old_types.Verification) (ret types.Verification) {
+	ret.Hash = old.Hash
+	return
+}
+
+func translateTLS(old old_types.TLS) (ret types.TLS) {
+	tr := newTranslator()
+	tr.Translate(&old.CertificateAuthorities, &ret.CertificateAuthorities)
+	return
+}
+
+func translateSecurity(old old_types.Security) (ret types.Security) {
+	ret.TLS = translateTLS(old.TLS)
+	return
+}
+
+func translateProxy(old old_types.Proxy) (ret types.Proxy) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.HTTPProxy, &ret.HTTPProxy)
+	tr.Translate(&old.HTTPSProxy, &ret.HTTPSProxy)
+	tr.Translate(&old.NoProxy, &ret.NoProxy)
+	return
+}
+
 func translateIgnition(old old_types.Ignition) (ret types.Ignition) {
 	// use a new translator so we don't recurse infinitely
-	translate.NewTranslator().Translate(&old, &ret)
+	tr := newTranslator()
+	tr.AddCustomTranslator(translateProxy)
+	tr.AddCustomTranslator(translateSecurity)
+	tr.Translate(&old.Config, &ret.Config)
+	tr.Translate(&old.Proxy, &ret.Proxy)
+	tr.Translate(&old.Security, &ret.Security)
+	tr.Translate(&old.Timeouts, &ret.Timeouts)
 	ret.Version = types.MaxVersion.String()
 	return
 }
 
+func translateFileEmbedded1(old old_types.FileEmbedded1) (ret types.FileEmbedded1) {
+	tr := newTranslator()
+	tr.Translate(&old.Append, &ret.Append)
+	tr.Translate(&old.Contents, &ret.Contents)
+	tr.Translate(&old.Mode, &ret.Mode)
+	return
+}
+
+func translateStorage(old old_types.Storage) (ret types.Storage) {
+	tr := newTranslator()
+	tr.AddCustomTranslator(translateFileEmbedded1)
+	tr.Translate(&old.Directories, &ret.Directories)
+	tr.Translate(&old.Disks, &ret.Disks)
+	tr.Translate(&old.Files, &ret.Files)
+	tr.Translate(&old.Filesystems, &ret.Filesystems)
+	tr.Translate(&old.Links, &ret.Links)
+	tr.Translate(&old.Luks, &ret.Luks)
+	tr.Translate(&old.Raid, &ret.Raid)
+	return
+}
+
+func translatePasswdUser(old old_types.PasswdUser) (ret types.PasswdUser) {
+	tr := translate.NewTranslator()
+	tr.Translate(&old.Gecos, &ret.Gecos)
+	tr.Translate(&old.Groups, &ret.Groups)
+	tr.Translate(&old.HomeDir, &ret.HomeDir)
+	tr.Translate(&old.Name, &ret.Name)
+	tr.Translate(&old.NoCreateHome, &ret.NoCreateHome)
+	tr.Translate(&old.NoLogInit, &ret.NoLogInit)
+	tr.Translate(&old.NoUserGroup, &ret.NoUserGroup)
+	tr.Translate(&old.PasswordHash, &ret.PasswordHash)
+	tr.Translate(&old.PrimaryGroup, &ret.PrimaryGroup)
+	tr.Translate(&old.SSHAuthorizedKeys, &ret.SSHAuthorizedKeys)
+	tr.Translate(&old.Shell, &ret.Shell)
+	tr.Translate(&old.ShouldExist, &ret.ShouldExist)
+	tr.Translate(&old.System, &ret.System)
+	tr.Translate(&old.UID, &ret.UID)
+	return
+}
+
+func translatePasswd(old old_types.Passwd) (ret types.Passwd) {
+	tr := translate.NewTranslator()
+	tr.AddCustomTranslator(translatePasswdUser)
+	tr.Translate(&old.Groups, &ret.Groups)
+	tr.Translate(&old.Users, &ret.Users)
+	return
+}
+
 func Translate(old old_types.Config) (ret types.Config) {
 	tr := translate.NewTranslator()
 	tr.AddCustomTranslator(translateIgnition)
+	tr.AddCustomTranslator(translatePasswd)
+	tr.AddCustomTranslator(translateStorage)
 	tr.Translate(&old, &ret)
 	return
 }
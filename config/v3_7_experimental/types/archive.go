@@ -0,0 +1,46 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (a Archive) Validate(c path.ContextPath) (r report.Report) {
+	r.Merge(a.Node.Validate(c))
+	r.AddOnError(c.Append("fileMode"), validateMode(a.FileMode))
+	r.AddOnError(c.Append("directoryMode"), validateMode(a.DirectoryMode))
+	r.AddOnError(c.Append("format"), a.validateFormat())
+	if a.Contents.Source == nil {
+		r.AddOnError(c.Append("contents", "source"), errors.ErrArchiveSourceRequired)
+	}
+	return
+}
+
+func (a Archive) validateFormat() error {
+	if util.NilOrEmpty(a.Format) {
+		return nil
+	}
+	switch *a.Format {
+	case "tar", "zip":
+		return nil
+	default:
+		return errors.ErrArchiveFormatInvalid
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestArchiveValidate(t *testing.T) {
+	tests := []struct {
+		in  Archive
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Archive{
+				Node: Node{Path: "/opt/app"},
+				ArchiveEmbedded1: ArchiveEmbedded1{
+					Contents: Resource{Source: util.StrToPtr("http://example.com/app.tar.gz")},
+				},
+			},
+		},
+		{
+			in: Archive{
+				Node: Node{Path: "/opt/app"},
+				ArchiveEmbedded1: ArchiveEmbedded1{
+					Contents:      Resource{Source: util.StrToPtr("http://example.com/app.zip")},
+					Format:        util.StrToPtr("zip"),
+					FileMode:      util.IntToPtr(0644),
+					DirectoryMode: util.IntToPtr(0755),
+				},
+			},
+		},
+		{
+			in: Archive{
+				Node: Node{Path: "/opt/app"},
+			},
+			at:  path.New("", "contents", "source"),
+			out: errors.ErrArchiveSourceRequired,
+		},
+		{
+			in: Archive{
+				Node: Node{Path: "/opt/app"},
+				ArchiveEmbedded1: ArchiveEmbedded1{
+					Contents: Resource{Source: util.StrToPtr("http://example.com/app.rar")},
+					Format:   util.StrToPtr("rar"),
+				},
+			},
+			at:  path.New("", "format"),
+			out: errors.ErrArchiveFormatInvalid,
+		},
+		{
+			in: Archive{
+				Node: Node{Path: "/opt/app"},
+				ArchiveEmbedded1: ArchiveEmbedded1{
+					Contents: Resource{Source: util.StrToPtr("http://example.com/app.tar")},
+					FileMode: util.IntToPtr(010000),
+				},
+			},
+			at:  path.New("", "fileMode"),
+			out: errors.ErrFileIllegalMode,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"slices"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (a Auth) IsPresent() bool {
+	return a.Type != nil || a.TokenSource != nil || a.AwsService != nil || a.AwsRegion != nil || a.Audience != nil
+}
+
+func (a Auth) Validate(c path.ContextPath) (r report.Report) {
+	if !a.IsPresent() {
+		return
+	}
+	if util.NilOrEmpty(a.Type) {
+		r.AddOnError(c.Append("type"), errors.ErrAuthTypeRequired)
+		return
+	}
+
+	// fields that may be set for each auth type
+	var allowed []string
+	switch *a.Type {
+	case "bearer":
+		allowed = []string{"tokenSource"}
+		if util.NilOrEmpty(a.TokenSource) {
+			r.AddOnError(c.Append("tokenSource"), errors.ErrAuthTokenSourceRequired)
+		} else {
+			r.AddOnError(c.Append("tokenSource"), validateURL(*a.TokenSource))
+		}
+	case "aws-sigv4":
+		allowed = []string{"awsService", "awsRegion"}
+		if util.NilOrEmpty(a.AwsService) {
+			r.AddOnError(c.Append("awsService"), errors.ErrAuthAwsServiceRequired)
+		}
+	case "gcp-identity":
+		allowed = []string{"audience"}
+	case "azure-managed-identity":
+		allowed = []string{"audience"}
+		if util.NilOrEmpty(a.Audience) {
+			r.AddOnError(c.Append("audience"), errors.ErrAuthAudienceRequired)
+		}
+	default:
+		r.AddOnError(c.Append("type"), errors.ErrAuthTypeInvalid)
+		return
+	}
+
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"tokenSource", a.TokenSource},
+		{"awsService", a.AwsService},
+		{"awsRegion", a.AwsRegion},
+		{"audience", a.Audience},
+	}
+	for _, f := range fields {
+		if f.value != nil && !slices.Contains(allowed, f.name) {
+			r.AddOnError(c.Append(f.name), errors.ErrAuthFieldNotApplicable)
+		}
+	}
+	return
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestAuthValidate(t *testing.T) {
+	tests := []struct {
+		in  Auth
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Auth{},
+		},
+		{
+			in: Auth{Type: util.StrToPtr("bearer"), TokenSource: util.StrToPtr("https://example.com/token")},
+		},
+		{
+			in: Auth{Type: util.StrToPtr("aws-sigv4"), AwsService: util.StrToPtr("execute-api"), AwsRegion: util.StrToPtr("us-east-1")},
+		},
+		{
+			in: Auth{Type: util.StrToPtr("gcp-identity")},
+		},
+		{
+			in: Auth{Type: util.StrToPtr("azure-managed-identity"), Audience: util.StrToPtr("api://example")},
+		},
+		{
+			in:  Auth{TokenSource: util.StrToPtr("https://example.com/token")},
+			at:  path.New("", "type"),
+			out: errors.ErrAuthTypeRequired,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("basic")},
+			at:  path.New("", "type"),
+			out: errors.ErrAuthTypeInvalid,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("bearer")},
+			at:  path.New("", "tokenSource"),
+			out: errors.ErrAuthTokenSourceRequired,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("bearer"), TokenSource: util.StrToPtr("nope://token")},
+			at:  path.New("", "tokenSource"),
+			out: errors.ErrInvalidScheme,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("aws-sigv4")},
+			at:  path.New("", "awsService"),
+			out: errors.ErrAuthAwsServiceRequired,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("azure-managed-identity")},
+			at:  path.New("", "audience"),
+			out: errors.ErrAuthAudienceRequired,
+		},
+		{
+			in:  Auth{Type: util.StrToPtr("gcp-identity"), AwsRegion: util.StrToPtr("us-east-1")},
+			at:  path.New("", "awsRegion"),
+			out: errors.ErrAuthFieldNotApplicable,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
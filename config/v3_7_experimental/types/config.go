@@ -46,6 +46,11 @@ func (cfg Config) Validate(c path.ContextPath) (r report.Report) {
 			}
 		}
 	}
+	for i, a := range cfg.Storage.Archives {
+		if _, exists := unitPaths[a.Path]; exists {
+			r.AddOnError(c.Append("storage", "archives", i, "path"), errors.ErrPathConflictsSystemd)
+		}
+	}
 	for i, f := range cfg.Storage.Files {
 		if _, exists := unitPaths[f.Path]; exists {
 			r.AddOnError(c.Append("storage", "files", i, "path"), errors.ErrPathConflictsSystemd)
@@ -61,5 +66,49 @@ func (cfg Config) Validate(c path.ContextPath) (r report.Report) {
 			r.AddOnError(c.Append("storage", "links", i, "path"), errors.ErrPathConflictsSystemd)
 		}
 	}
+	r.Merge(cfg.validateResources(c))
+	return
+}
+
+// validateResources ensures that signatures are only specified on config
+// references and encryption only on resources which support decrypting, since
+// they aren't checked anywhere else.
+func (cfg Config) validateResources(c path.ContextPath) (r report.Report) {
+	check := func(res Resource, encryptable bool, p ...interface{}) {
+		c := c.Append(p...)
+		if res.Verification.Signature != nil {
+			r.AddOnError(c.Append("verification", "signature"), errors.ErrSignatureNotSupported)
+		}
+		if !encryptable && res.Encryption.IsPresent() {
+			r.AddOnError(c.Append("encryption"), errors.ErrEncryptionNotSupported)
+		}
+	}
+	check(cfg.Ignition.Proxy.AutoConfig, false, "ignition", "proxy", "autoConfig")
+	check(cfg.Ignition.Proxy.Credentials, true, "ignition", "proxy", "credentials")
+	for i, ca := range cfg.Ignition.Security.TLS.CertificateAuthorities {
+		check(ca, false, "ignition", "security", "tls", "certificateAuthorities", i)
+	}
+	check(cfg.Ignition.Security.TLS.ClientCertificate, false, "ignition", "security", "tls", "clientCertificate")
+	check(cfg.Ignition.Security.TLS.ClientKey, true, "ignition", "security", "tls", "clientKey")
+	check(cfg.Ignition.Security.Vault.SecretID, true, "ignition", "security", "vault", "secretId")
+	check(cfg.Ignition.Security.Vault.Jwt, true, "ignition", "security", "vault", "jwt")
+	for i, key := range cfg.Ignition.Security.Signatures.TrustedKeys {
+		check(key, false, "ignition", "security", "signatures", "trustedKeys", i)
+	}
+	for i, u := range cfg.Passwd.Users {
+		check(u.PasswordHashSource, true, "passwd", "users", i, "passwordHashSource")
+	}
+	for i, a := range cfg.Storage.Archives {
+		check(a.Contents, false, "storage", "archives", i, "contents")
+	}
+	for i, f := range cfg.Storage.Files {
+		check(f.Contents, true, "storage", "files", i, "contents")
+		for j, app := range f.Append {
+			check(app, true, "storage", "files", i, "append", j)
+		}
+	}
+	for i, l := range cfg.Storage.Luks {
+		check(l.KeyFile, true, "storage", "luks", i, "keyFile")
+	}
 	return
 }
@@ -248,6 +248,67 @@ func TestConfigValidation(t *testing.T) {
 				},
 			},
 		},
+		// test 7: signed file contents, error
+		{
+			in: Config{
+				Storage: Storage{
+					Files: []File{
+						{
+							Node: Node{Path: "/etc/foo"},
+							FileEmbedded1: FileEmbedded1{
+								Contents: Resource{
+									Source: util.StrToPtr("https://example.com/foo"),
+									Verification: Verification{
+										Signature: util.StrToPtr("https://example.com/foo.sig"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			out: errors.ErrSignatureNotSupported,
+			at:  path.New("json", "storage", "files", 0, "contents", "verification", "signature"),
+		},
+		// test 8: signed config reference, no error
+		{
+			in: Config{
+				Ignition: Ignition{
+					Config: IgnitionConfig{
+						Merge: []Resource{
+							{
+								Source: util.StrToPtr("https://example.com/config.ign"),
+								Verification: Verification{
+									Signature: util.StrToPtr("https://example.com/config.ign.sig"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		// test 9: encrypted archive, error
+		{
+			in: Config{
+				Storage: Storage{
+					Archives: []Archive{
+						{
+							Node: Node{Path: "/opt/app"},
+							ArchiveEmbedded1: ArchiveEmbedded1{
+								Contents: Resource{
+									Source: util.StrToPtr("https://example.com/app.tar"),
+									Encryption: Encryption{
+										KeyFile: util.StrToPtr("/etc/ignition/age.key"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			out: errors.ErrEncryptionNotSupported,
+			at:  path.New("json", "storage", "archives", 0, "contents", "encryption"),
+		},
 	}
 	for i, test := range tests {
 		r := test.in.Validate(path.New("json"))
@@ -0,0 +1,53 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (e Encryption) IsPresent() bool {
+	return e.Format != nil || e.KeyFile != nil || e.KeyringKey != nil || e.SealedKeyFile != nil
+}
+
+func (e Encryption) Validate(c path.ContextPath) (r report.Report) {
+	if !e.IsPresent() {
+		return
+	}
+	if e.Format != nil && *e.Format != "age" {
+		r.AddOnError(c.Append("format"), errors.ErrEncryptionFormatInvalid)
+	}
+
+	keys := 0
+	for _, key := range []*string{e.KeyFile, e.KeyringKey, e.SealedKeyFile} {
+		if util.NotEmpty(key) {
+			keys++
+		}
+	}
+	switch {
+	case keys == 0:
+		r.AddOnError(c, errors.ErrEncryptionKeyRequired)
+	case keys > 1:
+		r.AddOnError(c, errors.ErrEncryptionKeyConflict)
+	}
+
+	r.AddOnError(c.Append("keyFile"), validatePathNilOK(e.KeyFile))
+	r.AddOnError(c.Append("sealedKeyFile"), validatePathNilOK(e.SealedKeyFile))
+	return
+}
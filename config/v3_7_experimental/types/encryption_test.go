@@ -0,0 +1,103 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestEncryptionValidate(t *testing.T) {
+	tests := []struct {
+		in  Encryption
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Encryption{},
+		},
+		{
+			in: Encryption{KeyFile: util.StrToPtr("/etc/ignition/age.key")},
+		},
+		{
+			in: Encryption{Format: util.StrToPtr("age"), SealedKeyFile: util.StrToPtr("/etc/ignition/age.key.jwe")},
+		},
+		{
+			in: Encryption{KeyringKey: util.StrToPtr("ignition:age")},
+		},
+		{
+			in:  Encryption{Format: util.StrToPtr("age")},
+			at:  path.New(""),
+			out: errors.ErrEncryptionKeyRequired,
+		},
+		{
+			in:  Encryption{KeyFile: util.StrToPtr("/etc/ignition/age.key"), KeyringKey: util.StrToPtr("ignition:age")},
+			at:  path.New(""),
+			out: errors.ErrEncryptionKeyConflict,
+		},
+		{
+			in:  Encryption{Format: util.StrToPtr("jwe"), KeyFile: util.StrToPtr("/etc/ignition/age.key")},
+			at:  path.New("", "format"),
+			out: errors.ErrEncryptionFormatInvalid,
+		},
+		{
+			in:  Encryption{KeyFile: util.StrToPtr("age.key")},
+			at:  path.New("", "keyFile"),
+			out: errors.ErrPathRelative,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
+
+func TestResourceEncryptionValidate(t *testing.T) {
+	encryption := Encryption{KeyFile: util.StrToPtr("/etc/ignition/age.key")}
+	tests := []struct {
+		in  Resource
+		out error
+	}{
+		{
+			in: Resource{Source: util.StrToPtr("data:,foo"), Encryption: encryption},
+		},
+		{
+			in:  Resource{Encryption: encryption},
+			out: errors.ErrEncryptionAndNilSource,
+		},
+		{
+			in:  Resource{Source: util.StrToPtr("data:,foo"), Compression: util.StrToPtr("gzip"), Encryption: encryption},
+			out: errors.ErrEncryptionAndCompression,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.validateEncryption()
+		if err != test.out {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out, err)
+		}
+	}
+}
@@ -26,9 +26,23 @@ func (f File) Validate(c path.ContextPath) (r report.Report) {
 	r.Merge(f.Node.Validate(c))
 	r.AddOnError(c.Append("mode"), validateMode(f.Mode))
 	r.AddOnError(c.Append("overwrite"), f.validateOverwrite())
+	r.AddOnError(c.Append("keepUnchanged"), f.validateKeepUnchanged())
 	return
 }
 
+func (f File) validateKeepUnchanged() error {
+	if !util.IsTrue(f.KeepUnchanged) {
+		return nil
+	}
+	if !util.IsTrue(f.Overwrite) {
+		return errors.ErrKeepUnchangedWithoutOverwrite
+	}
+	if util.NilOrEmpty(f.Contents.Verification.Hash) {
+		return errors.ErrKeepUnchangedWithoutHash
+	}
+	return nil
+}
+
 func (f File) validateOverwrite() error {
 	if util.IsTrue(f.Overwrite) && f.Contents.Source == nil {
 		return errors.ErrOverwriteAndNilSource
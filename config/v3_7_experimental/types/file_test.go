@@ -74,6 +74,66 @@ func TestFileValidateOverwrite(t *testing.T) {
 	}
 }
 
+func TestFileValidateKeepUnchanged(t *testing.T) {
+	contents := Resource{
+		Source: util.StrToPtr("http://example.com"),
+		Verification: Verification{
+			Hash: util.StrToPtr("sha512-00"),
+		},
+	}
+	tests := []struct {
+		in  File
+		out error
+	}{
+		{
+			File{},
+			nil,
+		},
+		{
+			File{
+				Node: Node{
+					Overwrite: util.BoolToPtr(true),
+				},
+				FileEmbedded1: FileEmbedded1{
+					Contents:      contents,
+					KeepUnchanged: util.BoolToPtr(true),
+				},
+			},
+			nil,
+		},
+		{
+			File{
+				FileEmbedded1: FileEmbedded1{
+					Contents:      contents,
+					KeepUnchanged: util.BoolToPtr(true),
+				},
+			},
+			errors.ErrKeepUnchangedWithoutOverwrite,
+		},
+		{
+			File{
+				Node: Node{
+					Overwrite: util.BoolToPtr(true),
+				},
+				FileEmbedded1: FileEmbedded1{
+					Contents: Resource{
+						Source: util.StrToPtr("http://example.com"),
+					},
+					KeepUnchanged: util.BoolToPtr(true),
+				},
+			},
+			errors.ErrKeepUnchangedWithoutHash,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.validateKeepUnchanged()
+		if test.out != err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out, err)
+		}
+	}
+}
+
 func TestFileContentsValidate(t *testing.T) {
 	tests := []struct {
 		in  Resource
@@ -106,6 +166,14 @@ func TestFileContentsValidate(t *testing.T) {
 			},
 			errors.ErrVerificationAndNilSource,
 		},
+		{
+			Resource{
+				Verification: Verification{
+					TransportHash: util.StrToPtr(""),
+				},
+			},
+			errors.ErrVerificationAndNilSource,
+		},
 	}
 
 	for i, test := range tests {
@@ -115,3 +183,90 @@ func TestFileContentsValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestFileContentsValidateSources(t *testing.T) {
+	tests := []struct {
+		in  Resource
+		out error
+	}{
+		{
+			Resource{},
+			nil,
+		},
+		{
+			Resource{
+				Source:  util.StrToPtr("https://example.com/file"),
+				Sources: []string{"s3://bucket/file"},
+			},
+			nil,
+		},
+		{
+			Resource{
+				Sources: []string{"s3://bucket/file"},
+			},
+			errors.ErrSourcesAndNilSource,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.validateSources()
+		if test.out != err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out, err)
+		}
+	}
+}
+
+func TestFileContentsValidateCompression(t *testing.T) {
+	tests := []struct {
+		in  Resource
+		out error
+	}{
+		{
+			Resource{},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr(""),
+			},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr("gzip"),
+			},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr("zstd"),
+			},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr("xz"),
+			},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr("bzip2"),
+			},
+			nil,
+		},
+		{
+			Resource{
+				Compression: util.StrToPtr("lz4"),
+			},
+			errors.ErrCompressionInvalid,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.validateCompression()
+		if test.out != err {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (n Network) Validate(c path.ContextPath) (r report.Report) {
+	if n.MaxBandwidth != nil && *n.MaxBandwidth < 1 {
+		r.AddOnError(c.Append("maxBandwidth"), errors.ErrMaxBandwidthInvalid)
+	}
+	if n.MaxConcurrentFetches != nil && *n.MaxConcurrentFetches < 1 {
+		r.AddOnError(c.Append("maxConcurrentFetches"), errors.ErrMaxConcurrentFetchesInvalid)
+	}
+	return
+}
+
+func (p Peers) Validate(c path.ContextPath) (r report.Report) {
+	if p.Port != nil && (*p.Port < 1 || *p.Port > 65535) {
+		r.AddOnError(c.Append("port"), errors.ErrPeerPortInvalid)
+	}
+	for i, address := range p.Addresses {
+		if _, err := util.ParsePeerAddress(address, util.DefaultPeerPort); err != nil {
+			r.AddOnError(c.Append("addresses", i), err)
+		}
+	}
+	return
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestNetworkValidate(t *testing.T) {
+	tests := []struct {
+		in  Network
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Network{},
+		},
+		{
+			in: Network{
+				MaxBandwidth:         util.IntToPtr(10000000),
+				MaxConcurrentFetches: util.IntToPtr(1),
+			},
+		},
+		{
+			in:  Network{MaxBandwidth: util.IntToPtr(0)},
+			at:  path.New("", "maxBandwidth"),
+			out: errors.ErrMaxBandwidthInvalid,
+		},
+		{
+			in:  Network{MaxConcurrentFetches: util.IntToPtr(-1)},
+			at:  path.New("", "maxConcurrentFetches"),
+			out: errors.ErrMaxConcurrentFetchesInvalid,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
+
+func TestPeersValidate(t *testing.T) {
+	tests := []struct {
+		in  Peers
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Peers{},
+		},
+		{
+			in: Peers{
+				Share:     util.BoolToPtr(true),
+				Discover:  util.BoolToPtr(true),
+				Port:      util.IntToPtr(8080),
+				Addresses: []string{"10.0.0.5", "10.0.0.6:8080", "node7.example.com", "fd00::8", "[fd00::9]:8080"},
+			},
+		},
+		{
+			in:  Peers{Port: util.IntToPtr(0)},
+			at:  path.New("", "port"),
+			out: errors.ErrPeerPortInvalid,
+		},
+		{
+			in:  Peers{Port: util.IntToPtr(65536)},
+			at:  path.New("", "port"),
+			out: errors.ErrPeerPortInvalid,
+		},
+		{
+			in:  Peers{Addresses: []string{"10.0.0.5", "10.0.0.6:http"}},
+			at:  path.New("", "addresses", 1),
+			out: errors.ErrPeerAddressInvalid,
+		},
+		{
+			in:  Peers{Addresses: []string{"http://10.0.0.5"}},
+			at:  path.New("", "addresses", 0),
+			out: errors.ErrPeerAddressInvalid,
+		},
+		{
+			in:  Peers{Addresses: []string{":8080"}},
+			at:  path.New("", "addresses", 0),
+			out: errors.ErrPeerAddressInvalid,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
@@ -14,6 +14,14 @@
 
 package types
 
+import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
 func (p PasswdUser) Key() string {
 	return p.Name
 }
@@ -21,3 +29,10 @@ func (p PasswdUser) Key() string {
 func (g PasswdGroup) Key() string {
 	return g.Name
 }
+
+func (p PasswdUser) Validate(c path.ContextPath) (r report.Report) {
+	if util.NotEmpty(p.PasswordHash) && util.NotEmpty(p.PasswordHashSource.Source) {
+		r.AddOnError(c.Append("passwordHashSource"), errors.ErrPasswordHashSourceConflict)
+	}
+	return
+}
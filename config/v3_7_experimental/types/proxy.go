@@ -18,6 +18,7 @@ import (
 	"net/url"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
 
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
@@ -26,6 +27,10 @@ import (
 func (p Proxy) Validate(c path.ContextPath) (r report.Report) {
 	validateProxyURL(p.HTTPProxy, c.Append("httpProxy"), &r, true)
 	validateProxyURL(p.HTTPSProxy, c.Append("httpsProxy"), &r, false)
+	if p.Credentials.Source != nil && p.HTTPProxy == nil && p.HTTPSProxy == nil &&
+		p.AutoConfig.Source == nil && !util.IsTrue(p.AutoDiscover) {
+		r.AddOnError(c.Append("credentials"), errors.ErrProxyCredentialsWithoutProxy)
+	}
 	return
 }
 
@@ -110,3 +110,39 @@ func TestValidateProxyURL(t *testing.T) {
 		}
 	}
 }
+
+func TestProxyValidateCredentials(t *testing.T) {
+	credentials := Resource{Source: util.StrToPtr("data:,user:pass")}
+	tests := []struct {
+		in  Proxy
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Proxy{},
+		},
+		{
+			in: Proxy{HTTPSProxy: util.StrToPtr("https://proxy.example.com"), Credentials: credentials},
+		},
+		{
+			in: Proxy{AutoConfig: Resource{Source: util.StrToPtr("http://wpad.example.com/wpad.dat")}, Credentials: credentials},
+		},
+		{
+			in: Proxy{AutoDiscover: util.BoolToPtr(true), Credentials: credentials},
+		},
+		{
+			in:  Proxy{AutoDiscover: util.BoolToPtr(false), Credentials: credentials},
+			at:  path.New("", "credentials"),
+			out: errors.ErrProxyCredentialsWithoutProxy,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package types
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
@@ -34,15 +35,22 @@ func (res Resource) Key() string {
 func (res Resource) Validate(c path.ContextPath) (r report.Report) {
 	r.AddOnError(c.Append("compression"), res.validateCompression())
 	r.AddOnError(c.Append("verification", "hash"), res.validateVerification())
+	r.AddOnError(c.Append("verification", "signature"), res.validateSignature())
+	r.AddOnError(c.Append("encryption"), res.validateEncryption())
 	r.AddOnError(c.Append("source"), validateURLNilOK(res.Source))
+	r.AddOnError(c.Append("sources"), res.validateSources())
+	for i, source := range res.Sources {
+		r.AddOnError(c.Append("sources", i), validateURL(source))
+	}
 	r.AddOnError(c.Append("httpHeaders"), res.validateSchemeForHTTPHeaders())
+	r.AddOnError(c.Append("auth"), res.validateAuth())
 	return
 }
 
 func (res Resource) validateCompression() error {
 	if res.Compression != nil {
 		switch *res.Compression {
-		case "", "gzip":
+		case "", "gzip", "zstd", "xz", "bzip2":
 		default:
 			return errors.ErrCompressionInvalid
 		}
@@ -51,12 +59,40 @@ func (res Resource) validateCompression() error {
 }
 
 func (res Resource) validateVerification() error {
-	if res.Verification.Hash != nil && res.Source == nil {
+	v := res.Verification
+	if (v.Hash != nil || len(v.Hashes) > 0 || v.TransportHash != nil) && res.Source == nil {
+		return errors.ErrVerificationAndNilSource
+	}
+	return nil
+}
+
+func (res Resource) validateSignature() error {
+	if res.Verification.Signature != nil && res.Source == nil {
 		return errors.ErrVerificationAndNilSource
 	}
 	return nil
 }
 
+func (res Resource) validateSources() error {
+	if len(res.Sources) > 0 && res.Source == nil {
+		return errors.ErrSourcesAndNilSource
+	}
+	return nil
+}
+
+func (res Resource) validateEncryption() error {
+	if !res.Encryption.IsPresent() {
+		return nil
+	}
+	if res.Source == nil {
+		return errors.ErrEncryptionAndNilSource
+	}
+	if util.NotEmpty(res.Compression) {
+		return errors.ErrEncryptionAndCompression
+	}
+	return nil
+}
+
 func (res Resource) validateSchemeForHTTPHeaders() error {
 	if len(res.HTTPHeaders) < 1 {
 		return nil
@@ -72,13 +108,41 @@ func (res Resource) validateSchemeForHTTPHeaders() error {
 	}
 
 	switch u.Scheme {
-	case "http", "https":
+	case "http", "https", "oci":
 		return nil
 	default:
 		return errors.ErrUnsupportedSchemeForHTTPHeaders
 	}
 }
 
+func (res Resource) validateAuth() error {
+	if !res.Auth.IsPresent() {
+		return nil
+	}
+
+	if util.NilOrEmpty(res.Source) {
+		return errors.ErrInvalidUrl
+	}
+
+	u, err := url.Parse(*res.Source)
+	if err != nil {
+		return errors.ErrInvalidUrl
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return errors.ErrUnsupportedSchemeForAuth
+	}
+
+	for _, header := range res.HTTPHeaders {
+		if strings.EqualFold(header.Name, "Authorization") {
+			return errors.ErrAuthAndAuthorizationHeader
+		}
+	}
+	return nil
+}
+
 // Ensure that the Source is specified and valid.  This is not called by
 // Resource.Validate() because some structs that embed Resource don't
 // require Source to be specified.  Containing structs that require Source
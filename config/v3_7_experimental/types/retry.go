@@ -0,0 +1,48 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (r Retry) IgnoreDuplicates() map[string]struct{} {
+	return map[string]struct{}{
+		"StatusCodes": {},
+	}
+}
+
+func (r Retry) Validate(c path.ContextPath) (rep report.Report) {
+	if r.MaxAttempts != nil && *r.MaxAttempts < 1 {
+		rep.AddOnError(c.Append("maxAttempts"), errors.ErrRetryMaxAttemptsInvalid)
+	}
+	if r.InitialBackoff != nil && *r.InitialBackoff < 0 {
+		rep.AddOnError(c.Append("initialBackoff"), errors.ErrRetryBackoffInvalid)
+	}
+	if r.MaxBackoff != nil && *r.MaxBackoff < 0 {
+		rep.AddOnError(c.Append("maxBackoff"), errors.ErrRetryBackoffInvalid)
+	} else if r.MaxBackoff != nil && r.InitialBackoff != nil && *r.MaxBackoff < *r.InitialBackoff {
+		rep.AddOnError(c.Append("maxBackoff"), errors.ErrRetryMaxBackoffTooSmall)
+	}
+	for i, code := range r.StatusCodes {
+		if code < 100 || code > 599 {
+			rep.AddOnError(c.Append("statusCodes", i), errors.ErrRetryStatusCodeInvalid)
+		}
+	}
+	return
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestRetryValidate(t *testing.T) {
+	tests := []struct {
+		in  Retry
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Retry{},
+		},
+		{
+			in: Retry{
+				MaxAttempts:    util.IntToPtr(5),
+				InitialBackoff: util.IntToPtr(0),
+				MaxBackoff:     util.IntToPtr(10000),
+				StatusCodes:    []int{429, 503},
+				TLSErrors:      util.BoolToPtr(false),
+			},
+		},
+		{
+			in:  Retry{MaxAttempts: util.IntToPtr(0)},
+			at:  path.New("", "maxAttempts"),
+			out: errors.ErrRetryMaxAttemptsInvalid,
+		},
+		{
+			in:  Retry{InitialBackoff: util.IntToPtr(-1)},
+			at:  path.New("", "initialBackoff"),
+			out: errors.ErrRetryBackoffInvalid,
+		},
+		{
+			in:  Retry{MaxBackoff: util.IntToPtr(-1)},
+			at:  path.New("", "maxBackoff"),
+			out: errors.ErrRetryBackoffInvalid,
+		},
+		{
+			in:  Retry{InitialBackoff: util.IntToPtr(1000), MaxBackoff: util.IntToPtr(500)},
+			at:  path.New("", "maxBackoff"),
+			out: errors.ErrRetryMaxBackoffTooSmall,
+		},
+		{
+			in:  Retry{StatusCodes: []int{429, 42}},
+			at:  path.New("", "statusCodes", 1),
+			out: errors.ErrRetryStatusCodeInvalid,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
@@ -2,6 +2,26 @@ package types
 
 // generated by "schematyper --package=types config/v3_7_experimental/schema/ignition.json -o config/v3_7_experimental/types/schema.go --root-type=Config" -- DO NOT EDIT
 
+type Archive struct {
+	Node
+	ArchiveEmbedded1
+}
+
+type ArchiveEmbedded1 struct {
+	Contents      Resource `json:"contents,omitempty"`
+	DirectoryMode *int     `json:"directoryMode,omitempty"`
+	FileMode      *int     `json:"fileMode,omitempty"`
+	Format        *string  `json:"format,omitempty"`
+}
+
+type Auth struct {
+	Audience    *string `json:"audience,omitempty"`
+	AwsRegion   *string `json:"awsRegion,omitempty"`
+	AwsService  *string `json:"awsService,omitempty"`
+	TokenSource *string `json:"tokenSource,omitempty"`
+	Type        *string `json:"type,omitempty"`
+}
+
 type Cex struct {
 	Enabled *bool `json:"enabled,omitempty"`
 }
@@ -49,15 +69,24 @@ type Dropin struct {
 	Name     string  `json:"name"`
 }
 
+type Encryption struct {
+	Format        *string `json:"format,omitempty"`
+	KeyFile       *string `json:"keyFile,omitempty"`
+	KeyringKey    *string `json:"keyringKey,omitempty"`
+	SealedKeyFile *string `json:"sealedKeyFile,omitempty"`
+}
+
 type File struct {
 	Node
 	FileEmbedded1
 }
 
 type FileEmbedded1 struct {
-	Append   []Resource `json:"append,omitempty"`
-	Contents Resource   `json:"contents,omitempty"`
-	Mode     *int       `json:"mode,omitempty"`
+	Append        []Resource `json:"append,omitempty"`
+	Contents      Resource   `json:"contents,omitempty"`
+	KeepUnchanged *bool      `json:"keepUnchanged,omitempty"`
+	Mode          *int       `json:"mode,omitempty"`
+	Template      *bool      `json:"template,omitempty"`
 }
 
 type Filesystem struct {
@@ -84,7 +113,9 @@ type HTTPHeaders []HTTPHeader
 
 type Ignition struct {
 	Config   IgnitionConfig `json:"config,omitempty"`
+	Network  Network        `json:"network,omitempty"`
 	Proxy    Proxy          `json:"proxy,omitempty"`
+	Retry    Retry          `json:"retry,omitempty"`
 	Security Security       `json:"security,omitempty"`
 	Timeouts Timeouts       `json:"timeouts,omitempty"`
 	Version  string         `json:"version"`
@@ -130,6 +161,12 @@ type LuksOption string
 
 type MountOption string
 
+type Network struct {
+	MaxBandwidth         *int  `json:"maxBandwidth,omitempty"`
+	MaxConcurrentFetches *int  `json:"maxConcurrentFetches,omitempty"`
+	Peers                Peers `json:"peers,omitempty"`
+}
+
 type NoProxyItem string
 
 type Node struct {
@@ -177,26 +214,37 @@ type PasswdGroup struct {
 }
 
 type PasswdUser struct {
-	Gecos             *string            `json:"gecos,omitempty"`
-	Groups            []Group            `json:"groups,omitempty"`
-	HomeDir           *string            `json:"homeDir,omitempty"`
-	Name              string             `json:"name"`
-	NoCreateHome      *bool              `json:"noCreateHome,omitempty"`
-	NoLogInit         *bool              `json:"noLogInit,omitempty"`
-	NoUserGroup       *bool              `json:"noUserGroup,omitempty"`
-	PasswordHash      *string            `json:"passwordHash,omitempty"`
-	PrimaryGroup      *string            `json:"primaryGroup,omitempty"`
-	SSHAuthorizedKeys []SSHAuthorizedKey `json:"sshAuthorizedKeys,omitempty"`
-	Shell             *string            `json:"shell,omitempty"`
-	ShouldExist       *bool              `json:"shouldExist,omitempty"`
-	System            *bool              `json:"system,omitempty"`
-	UID               *int               `json:"uid,omitempty"`
+	Gecos              *string            `json:"gecos,omitempty"`
+	Groups             []Group            `json:"groups,omitempty"`
+	HomeDir            *string            `json:"homeDir,omitempty"`
+	Name               string             `json:"name"`
+	NoCreateHome       *bool              `json:"noCreateHome,omitempty"`
+	NoLogInit          *bool              `json:"noLogInit,omitempty"`
+	NoUserGroup        *bool              `json:"noUserGroup,omitempty"`
+	PasswordHash       *string            `json:"passwordHash,omitempty"`
+	PasswordHashSource Resource           `json:"passwordHashSource,omitempty"`
+	PrimaryGroup       *string            `json:"primaryGroup,omitempty"`
+	SSHAuthorizedKeys  []SSHAuthorizedKey `json:"sshAuthorizedKeys,omitempty"`
+	Shell              *string            `json:"shell,omitempty"`
+	ShouldExist        *bool              `json:"shouldExist,omitempty"`
+	System             *bool              `json:"system,omitempty"`
+	UID                *int               `json:"uid,omitempty"`
+}
+
+type Peers struct {
+	Addresses []string `json:"addresses,omitempty"`
+	Discover  *bool    `json:"discover,omitempty"`
+	Port      *int     `json:"port,omitempty"`
+	Share     *bool    `json:"share,omitempty"`
 }
 
 type Proxy struct {
-	HTTPProxy  *string       `json:"httpProxy,omitempty"`
-	HTTPSProxy *string       `json:"httpsProxy,omitempty"`
-	NoProxy    []NoProxyItem `json:"noProxy,omitempty"`
+	AutoConfig   Resource      `json:"autoConfig,omitempty"`
+	AutoDiscover *bool         `json:"autoDiscover,omitempty"`
+	Credentials  Resource      `json:"credentials,omitempty"`
+	HTTPProxy    *string       `json:"httpProxy,omitempty"`
+	HTTPSProxy   *string       `json:"httpsProxy,omitempty"`
+	NoProxy      []NoProxyItem `json:"noProxy,omitempty"`
 }
 
 type Raid struct {
@@ -210,19 +258,38 @@ type Raid struct {
 type RaidOption string
 
 type Resource struct {
+	Auth         Auth         `json:"auth,omitempty"`
 	Compression  *string      `json:"compression,omitempty"`
+	Encryption   Encryption   `json:"encryption,omitempty"`
 	HTTPHeaders  HTTPHeaders  `json:"httpHeaders,omitempty"`
 	Source       *string      `json:"source,omitempty"`
+	Sources      []string     `json:"sources,omitempty"`
 	Verification Verification `json:"verification,omitempty"`
 }
 
 type SSHAuthorizedKey string
 
+type Retry struct {
+	InitialBackoff *int  `json:"initialBackoff,omitempty"`
+	MaxAttempts    *int  `json:"maxAttempts,omitempty"`
+	MaxBackoff     *int  `json:"maxBackoff,omitempty"`
+	StatusCodes    []int `json:"statusCodes,omitempty"`
+	TLSErrors      *bool `json:"tlsErrors,omitempty"`
+}
+
 type Security struct {
-	TLS TLS `json:"tls,omitempty"`
+	Signatures Signatures `json:"signatures,omitempty"`
+	TLS        TLS        `json:"tls,omitempty"`
+	Vault      Vault      `json:"vault,omitempty"`
+}
+
+type Signatures struct {
+	Required    *bool      `json:"required,omitempty"`
+	TrustedKeys []Resource `json:"trustedKeys,omitempty"`
 }
 
 type Storage struct {
+	Archives    []Archive    `json:"archives,omitempty"`
 	Directories []Directory  `json:"directories,omitempty"`
 	Disks       []Disk       `json:"disks,omitempty"`
 	Files       []File       `json:"files,omitempty"`
@@ -238,6 +305,11 @@ type Systemd struct {
 
 type TLS struct {
 	CertificateAuthorities []Resource `json:"certificateAuthorities,omitempty"`
+	CipherSuites           []string   `json:"cipherSuites,omitempty"`
+	ClientCertificate      Resource   `json:"clientCertificate,omitempty"`
+	ClientKey              Resource   `json:"clientKey,omitempty"`
+	MinVersion             *string    `json:"minVersion,omitempty"`
+	PinnedPublicKeys       []string   `json:"pinnedPublicKeys,omitempty"`
 }
 
 type Tang struct {
@@ -260,5 +332,18 @@ type Unit struct {
 }
 
 type Verification struct {
-	Hash *string `json:"hash,omitempty"`
+	Hash          *string  `json:"hash,omitempty"`
+	Hashes        []string `json:"hashes,omitempty"`
+	Signature     *string  `json:"signature,omitempty"`
+	TransportHash *string  `json:"transportHash,omitempty"`
+}
+
+type Vault struct {
+	AuthMethod *string  `json:"authMethod,omitempty"`
+	AuthMount  *string  `json:"authMount,omitempty"`
+	Jwt        Resource `json:"jwt,omitempty"`
+	Namespace  *string  `json:"namespace,omitempty"`
+	Role       *string  `json:"role,omitempty"`
+	RoleID     *string  `json:"roleId,omitempty"`
+	SecretID   Resource `json:"secretId,omitempty"`
 }
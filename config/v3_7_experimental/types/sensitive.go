@@ -0,0 +1,55 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// Sensitive is implemented by config types with fields that may contain
+// secrets, so that they can be redacted when the config is logged.
+type Sensitive interface {
+	// SensitiveFields returns the names of the struct fields that may
+	// contain secrets.
+	SensitiveFields() []string
+}
+
+func (Auth) SensitiveFields() []string {
+	return []string{"TokenSource"}
+}
+
+func (HTTPHeader) SensitiveFields() []string {
+	return []string{"Value"}
+}
+
+func (Luks) SensitiveFields() []string {
+	return []string{"KeyFile"}
+}
+
+func (PasswdGroup) SensitiveFields() []string {
+	return []string{"PasswordHash"}
+}
+
+func (PasswdUser) SensitiveFields() []string {
+	return []string{"PasswordHash", "PasswordHashSource"}
+}
+
+func (Proxy) SensitiveFields() []string {
+	return []string{"Credentials"}
+}
+
+func (TLS) SensitiveFields() []string {
+	return []string{"ClientKey"}
+}
+
+func (Vault) SensitiveFields() []string {
+	return []string{"Jwt", "SecretID"}
+}
@@ -27,6 +27,7 @@ import (
 
 func (s Storage) MergedKeys() map[string]string {
 	return map[string]string{
+		"Archives":    "Node",
 		"Directories": "Node",
 		"Files":       "Node",
 		"Links":       "Node",
@@ -34,6 +35,7 @@ func (s Storage) MergedKeys() map[string]string {
 }
 
 func (s Storage) Validate(c vpath.ContextPath) (r report.Report) {
+	s.validateArchives(c, &r)
 	s.validateDirectories(c, &r)
 	s.validateFiles(c, &r)
 	s.validateLinks(c, &r)
@@ -41,6 +43,16 @@ func (s Storage) Validate(c vpath.ContextPath) (r report.Report) {
 	return
 }
 
+func (s Storage) validateArchives(c vpath.ContextPath, r *report.Report) {
+	for i, a := range s.Archives {
+		for _, l := range s.Links {
+			if strings.HasPrefix(a.Path, l.Path+"/") {
+				r.AddOnError(c.Append("archives", i), errors.ErrArchiveUsedSymlink)
+			}
+		}
+	}
+}
+
 func (s Storage) validateDirectories(c vpath.ContextPath, r *report.Report) {
 	for i, d := range s.Directories {
 		for _, l := range s.Links {
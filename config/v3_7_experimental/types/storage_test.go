@@ -97,6 +97,24 @@ func TestStorageValidateErrors(t *testing.T) {
 			err: errors.ErrDirectoryUsedSymlink,
 			at:  path.New("", "directories", 0),
 		},
+		// test when an archive uses a configured symlink path returns ErrArchiveUsedSymlink
+		{
+			in: Storage{
+				Links: []Link{
+					{
+						Node:          Node{Path: "/foo"},
+						LinkEmbedded1: LinkEmbedded1{Target: util.StrToPtr("/foo-t")},
+					},
+				},
+				Archives: []Archive{
+					{
+						Node: Node{Path: "/foo/bar"},
+					},
+				},
+			},
+			err: errors.ErrArchiveUsedSymlink,
+			at:  path.New("", "archives", 0),
+		},
 		// test the same path listed for two separate symlinks returns ErrLinkUsedSymlink
 		{
 			in: Storage{
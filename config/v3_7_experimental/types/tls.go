@@ -15,6 +15,9 @@
 package types
 
 import (
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
 )
@@ -23,5 +26,23 @@ func (tls TLS) Validate(c path.ContextPath) (r report.Report) {
 	for i, ca := range tls.CertificateAuthorities {
 		r.AddOnError(c.Append("certificateAuthorities", i), ca.validateRequiredSource())
 	}
+	for i, pin := range tls.PinnedPublicKeys {
+		_, err := util.ParsePublicKeyPin(pin)
+		r.AddOnError(c.Append("pinnedPublicKeys", i), err)
+	}
+	if util.NotEmpty(tls.MinVersion) {
+		_, err := util.ParseTLSVersion(*tls.MinVersion)
+		r.AddOnError(c.Append("minVersion"), err)
+	}
+	for i, name := range tls.CipherSuites {
+		_, err := util.ParseCipherSuite(name)
+		r.AddOnError(c.Append("cipherSuites", i), err)
+	}
+	if util.NotEmpty(tls.ClientCertificate.Source) && util.NilOrEmpty(tls.ClientKey.Source) {
+		r.AddOnError(c.Append("clientKey"), errors.ErrClientCertificateKeyPair)
+	}
+	if util.NotEmpty(tls.ClientKey.Source) && util.NilOrEmpty(tls.ClientCertificate.Source) {
+		r.AddOnError(c.Append("clientCertificate"), errors.ErrClientCertificateKeyPair)
+	}
 	return
 }
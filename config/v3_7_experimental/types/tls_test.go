@@ -17,6 +17,8 @@ package types
 import (
 	"testing"
 
+	"github.com/coreos/ignition/v2/config/util"
+
 	"github.com/coreos/vcontext/validate"
 )
 
@@ -31,6 +33,42 @@ func TestTLSValidate(t *testing.T) {
 			},
 			"error at $.certificateAuthorities.0: source is required\n",
 		},
+		{
+			TLS{
+				ClientCertificate: Resource{Source: util.StrToPtr("data:,cert")},
+				ClientKey:         Resource{Source: util.StrToPtr("data:,key")},
+			},
+			"",
+		},
+		{
+			TLS{
+				ClientCertificate: Resource{Source: util.StrToPtr("data:,cert")},
+			},
+			"error at $.clientKey: clientCertificate and clientKey must be specified together\n",
+		},
+		{
+			TLS{
+				PinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+				MinVersion:       util.StrToPtr("1.3"),
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			},
+			"",
+		},
+		{
+			TLS{
+				PinnedPublicKeys: []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", "sha256/AAAA"},
+			},
+			"error at $.pinnedPublicKeys.0: public key pin must be \"sha256/\" followed by a base64-encoded SHA-256 hash\n" +
+				"error at $.pinnedPublicKeys.1: public key pin must be \"sha256/\" followed by a base64-encoded SHA-256 hash\n",
+		},
+		{
+			TLS{
+				MinVersion:   util.StrToPtr("1.1"),
+				CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			},
+			"error at $.minVersion: TLS version must be 1.2 or 1.3\n" +
+				"error at $.cipherSuites.0: unknown or insecure TLS cipher suite\n",
+		},
 	}
 
 	for i, test := range tests {
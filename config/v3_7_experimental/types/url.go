@@ -16,63 +16,23 @@ package types
 
 import (
 	"net/url"
-	"strings"
-
-	"github.com/aws/aws-sdk-go-v2/aws/arn"
-	"github.com/vincent-petithory/dataurl"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
 )
 
+// validateURL validates s with the validator of its source scheme, which
+// is either built in or registered by a program embedding Ignition.
 func validateURL(s string) error {
 	u, err := url.Parse(s)
 	if err != nil {
 		return errors.ErrInvalidUrl
 	}
-
-	switch u.Scheme {
-	case "http", "https", "tftp", "gs":
-		return nil
-	case "s3":
-		if v, ok := u.Query()["versionId"]; ok {
-			if len(v) == 0 || v[0] == "" {
-				return errors.ErrInvalidS3ObjectVersionId
-			}
-		}
-		return nil
-	case "arn":
-		fullURL := u.Scheme + ":" + u.Opaque
-		if !arn.IsARN(fullURL) {
-			return errors.ErrInvalidS3ARN
-		}
-		s3arn, err := arn.Parse(fullURL)
-		if err != nil {
-			return err
-		}
-		if s3arn.Service != "s3" {
-			return errors.ErrInvalidS3ARN
-		}
-		urlSplit := strings.Split(fullURL, "/")
-		if strings.HasPrefix(s3arn.Resource, "accesspoint/") && len(urlSplit) < 3 {
-			return errors.ErrInvalidS3ARN
-		} else if len(urlSplit) < 2 {
-			return errors.ErrInvalidS3ARN
-		}
-		if v, ok := u.Query()["versionId"]; ok {
-			if len(v) == 0 || v[0] == "" {
-				return errors.ErrInvalidS3ObjectVersionId
-			}
-		}
-		return nil
-	case "data":
-		if _, err := dataurl.DecodeString(s); err != nil {
-			return err
-		}
-		return nil
-	default:
+	validate, ok := util.LookupScheme(u.Scheme)
+	if !ok {
 		return errors.ErrInvalidScheme
 	}
+	return validate(*u)
 }
 
 func validateURLNilOK(s *string) error {
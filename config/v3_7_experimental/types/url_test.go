@@ -126,6 +126,78 @@ func TestURLValidate(t *testing.T) {
 			util.StrToPtr("gs://bucket/object"),
 			nil,
 		},
+		{
+			util.StrToPtr("oci://registry.example.com/org/artifact"),
+			nil,
+		},
+		{
+			util.StrToPtr("oci://registry.example.com:5000/org/artifact:v1.0?file=tool"),
+			nil,
+		},
+		{
+			util.StrToPtr("oci://registry.example.com/artifact@sha256:4f1a5a7d7d8e8c2c6b1f4a0c0e0a2b2d0f2a1c6e4d1f0b2a3c4d5e6f7a8b9c0d"),
+			nil,
+		},
+		{
+			util.StrToPtr("oci://registry.example.com/artifact@sha256:bad"),
+			errors.ErrInvalidOCIReference,
+		},
+		{
+			util.StrToPtr("oci://registry.example.com/Upper"),
+			errors.ErrInvalidOCIReference,
+		},
+		{
+			util.StrToPtr("oci:///artifact"),
+			errors.ErrInvalidOCIReference,
+		},
+		{
+			util.StrToPtr("vault://vault.example.com:8200/secret/data/node?field=password&version=2"),
+			nil,
+		},
+		{
+			util.StrToPtr("vault://vault.example.com/transit/decrypt/disk?ciphertext=vault:v1:c2VjcmV0"),
+			nil,
+		},
+		{
+			util.StrToPtr("vault://vault.example.com/secret/node?version=0"),
+			errors.ErrInvalidVaultURL,
+		},
+		{
+			util.StrToPtr("vault://vault.example.com/transit/disk?ciphertext=vault:v1:c2VjcmV0"),
+			errors.ErrInvalidVaultURL,
+		},
+		{
+			util.StrToPtr("vault:///secret/node"),
+			errors.ErrInvalidVaultURL,
+		},
+		{
+			util.StrToPtr("file:///usr/share/payload/tool"),
+			nil,
+		},
+		{
+			util.StrToPtr("file://localhost/usr/share/payload/tool"),
+			nil,
+		},
+		{
+			util.StrToPtr("file://host/tool"),
+			errors.ErrInvalidFileURL,
+		},
+		{
+			util.StrToPtr("file:tool"),
+			errors.ErrInvalidFileURL,
+		},
+		{
+			util.StrToPtr("block://PAYLOAD/bin/tool"),
+			nil,
+		},
+		{
+			util.StrToPtr("block://PAYLOAD/"),
+			errors.ErrInvalidBlockURL,
+		},
+		{
+			util.StrToPtr("block:///bin/tool"),
+			errors.ErrInvalidBlockURL,
+		},
 	}
 
 	for i, test := range tests {
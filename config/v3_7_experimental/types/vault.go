@@ -0,0 +1,76 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"slices"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func (v Vault) IsPresent() bool {
+	return v.AuthMethod != nil || v.AuthMount != nil || v.Namespace != nil || v.Role != nil || v.RoleID != nil ||
+		v.Jwt.Source != nil || v.SecretID.Source != nil
+}
+
+func (v Vault) Validate(c path.ContextPath) (r report.Report) {
+	if !v.IsPresent() {
+		return
+	}
+	if util.NilOrEmpty(v.AuthMethod) {
+		r.AddOnError(c.Append("authMethod"), errors.ErrVaultAuthMethodRequired)
+		return
+	}
+
+	// fields that may be set for each auth method
+	var allowed []string
+	switch *v.AuthMethod {
+	case "approle":
+		allowed = []string{"roleId", "secretId"}
+		if util.NilOrEmpty(v.RoleID) {
+			r.AddOnError(c.Append("roleId"), errors.ErrVaultRoleIDRequired)
+		}
+		r.AddOnError(c.Append("secretId"), v.SecretID.validateRequiredSource())
+	case "jwt":
+		allowed = []string{"role", "jwt"}
+		if util.NilOrEmpty(v.Role) {
+			r.AddOnError(c.Append("role"), errors.ErrVaultRoleRequired)
+		}
+		r.AddOnError(c.Append("jwt"), v.Jwt.validateRequiredSource())
+	default:
+		r.AddOnError(c.Append("authMethod"), errors.ErrVaultAuthMethodInvalid)
+		return
+	}
+
+	fields := []struct {
+		name    string
+		present bool
+	}{
+		{"role", v.Role != nil},
+		{"roleId", v.RoleID != nil},
+		{"secretId", v.SecretID.Source != nil},
+		{"jwt", v.Jwt.Source != nil},
+	}
+	for _, f := range fields {
+		if f.present && !slices.Contains(allowed, f.name) {
+			r.AddOnError(c.Append(f.name), errors.ErrAuthFieldNotApplicable)
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+func TestVaultValidate(t *testing.T) {
+	secretID := Resource{Source: util.StrToPtr("data:,secret")}
+	jwt := Resource{Source: util.StrToPtr("http://169.254.169.254/token")}
+	tests := []struct {
+		in  Vault
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Vault{},
+		},
+		{
+			in: Vault{AuthMethod: util.StrToPtr("approle"), RoleID: util.StrToPtr("role"), SecretID: secretID},
+		},
+		{
+			in: Vault{AuthMethod: util.StrToPtr("jwt"), AuthMount: util.StrToPtr("gcp-jwt"), Role: util.StrToPtr("node"), Jwt: jwt, Namespace: util.StrToPtr("ns1")},
+		},
+		{
+			in:  Vault{RoleID: util.StrToPtr("role")},
+			at:  path.New("", "authMethod"),
+			out: errors.ErrVaultAuthMethodRequired,
+		},
+		{
+			in:  Vault{AuthMethod: util.StrToPtr("token")},
+			at:  path.New("", "authMethod"),
+			out: errors.ErrVaultAuthMethodInvalid,
+		},
+		{
+			in:  Vault{AuthMethod: util.StrToPtr("approle"), SecretID: secretID},
+			at:  path.New("", "roleId"),
+			out: errors.ErrVaultRoleIDRequired,
+		},
+		{
+			in:  Vault{AuthMethod: util.StrToPtr("approle"), RoleID: util.StrToPtr("role")},
+			at:  path.New("", "secretId"),
+			out: errors.ErrSourceRequired,
+		},
+		{
+			in:  Vault{AuthMethod: util.StrToPtr("jwt"), Jwt: jwt},
+			at:  path.New("", "role"),
+			out: errors.ErrVaultRoleRequired,
+		},
+		{
+			in:  Vault{AuthMethod: util.StrToPtr("jwt"), Role: util.StrToPtr("node"), Jwt: jwt, SecretID: secretID},
+			at:  path.New("", "secretId"),
+			out: errors.ErrAuthFieldNotApplicable,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
@@ -25,6 +25,17 @@ import (
 	"github.com/coreos/vcontext/report"
 )
 
+// hashFunctions are the supported hash functions, by the name used in a
+// hash specifier.
+var hashFunctions = map[string]crypto.Hash{
+	"sha256":      crypto.SHA256,
+	"sha384":      crypto.SHA384,
+	"sha512":      crypto.SHA512,
+	"sha3-256":    crypto.SHA3_256,
+	"sha3-512":    crypto.SHA3_512,
+	"blake2b-512": crypto.BLAKE2b_512,
+}
+
 // HashParts will return the sum and function (in that order) of the hash stored
 // in this Verification, or an error if there is an issue during parsing.
 func (v Verification) HashParts() (string, string, error) {
@@ -32,40 +43,44 @@ func (v Verification) HashParts() (string, string, error) {
 		// The hash can be nil
 		return "", "", nil
 	}
-	parts := strings.SplitN(*v.Hash, "-", 2)
-	if len(parts) != 2 {
+	return splitHash(*v.Hash)
+}
+
+// splitHash splits a hash specifier into its function and sum. Function
+// names may contain dashes, but sums don't.
+func splitHash(s string) (string, string, error) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
 		return "", "", errors.ErrHashMalformed
 	}
-
-	return parts[0], parts[1], nil
+	return s[:i], s[i+1:], nil
 }
 
 func (v Verification) Validate(c path.ContextPath) (r report.Report) {
-	c = c.Append("hash")
-	if v.Hash == nil {
-		// The hash can be nil
-		return
+	r.AddOnError(c.Append("signature"), validateURLNilOK(v.Signature))
+	if v.Hash != nil {
+		r.AddOnError(c.Append("hash"), validateHash(*v.Hash))
 	}
+	for i, h := range v.Hashes {
+		r.AddOnError(c.Append("hashes", i), validateHash(h))
+	}
+	if v.TransportHash != nil {
+		r.AddOnError(c.Append("transportHash"), validateHash(*v.TransportHash))
+	}
+	return
+}
 
-	function, sum, err := v.HashParts()
+func validateHash(s string) error {
+	function, sum, err := splitHash(s)
 	if err != nil {
-		r.AddOnError(c, err)
-		return
+		return err
 	}
-	var hash crypto.Hash
-	switch function {
-	case "sha512":
-		hash = crypto.SHA512
-	case "sha256":
-		hash = crypto.SHA256
-	default:
-		r.AddOnError(c, errors.ErrHashUnrecognized)
-		return
+	hash, ok := hashFunctions[function]
+	if !ok {
+		return errors.ErrHashUnrecognized
 	}
-
 	if len(sum) != hex.EncodedLen(hash.Size()) {
-		r.AddOnError(c, errors.ErrHashWrongSize)
+		return errors.ErrHashWrongSize
 	}
-
-	return
+	return nil
 }
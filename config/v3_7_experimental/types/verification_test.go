@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/config/util"
 
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
@@ -64,6 +65,12 @@ func TestHashValidate(t *testing.T) {
 	h3 := "sha512-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	h4 := "sha256-0519a9826023338828942b081814355d55301b9bc82042390f9afaf75cd3a707"
 	h5 := "sha256-345"
+	h6 := "sha384-59e1748777448c69de6b800d7a33bbfb9ff1b463e44354c3553bcdb9c666fa90125a3c79f90397bdf5f6a13de828684f"
+	h7 := "sha3-256-3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392"
+	h8 := "sha3-512-75d527c368f2efe848ecf6b073a36767800805e9eef2b1857d5f984f036eb6df891d75f72d9b154518c1cd58835286d1da9a38deba3de98b5a53e5ed78a84976"
+	h9 := "blake2b-512-e4cfa39a3d37be31c59609e807970799caa68a19bfaa15135f165085e01d41a65ba1e1b146aeb6bd0092b49eac214c103ccfa3a365954bbbe52f74a2b3620c94"
+	h10 := "sha3-256-345"
+	h11 := "sha3-0519a9826023338828942b081814355d55301b9bc82042390f9afaf75cd3a707"
 
 	tests := []struct {
 		in  Verification
@@ -89,6 +96,30 @@ func TestHashValidate(t *testing.T) {
 			Verification{Hash: &h5},
 			errors.ErrHashWrongSize,
 		},
+		{
+			Verification{Hash: &h6},
+			nil,
+		},
+		{
+			Verification{Hash: &h7},
+			nil,
+		},
+		{
+			Verification{Hash: &h8},
+			nil,
+		},
+		{
+			Verification{Hash: &h9},
+			nil,
+		},
+		{
+			Verification{Hash: &h10},
+			errors.ErrHashWrongSize,
+		},
+		{
+			Verification{Hash: &h11},
+			errors.ErrHashUnrecognized,
+		},
 	}
 
 	for i, test := range tests {
@@ -100,3 +131,69 @@ func TestHashValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestHashesValidate(t *testing.T) {
+	tests := []struct {
+		in  Verification
+		at  path.ContextPath
+		out error
+	}{
+		{
+			in: Verification{Hashes: []string{
+				"sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				"sha3-256-3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392",
+			}},
+		},
+		{
+			in: Verification{Hashes: []string{
+				"sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				"md5-5d41402abc4b2a76b9719d911017c592",
+			}},
+			at:  path.New("", "hashes", 1),
+			out: errors.ErrHashUnrecognized,
+		},
+		{
+			in:  Verification{TransportHash: util.StrToPtr("sha256-345")},
+			at:  path.New("", "transportHash"),
+			out: errors.ErrHashWrongSize,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.Validate(path.New(""))
+		expected := report.Report{}
+		expected.AddOnError(test.at, test.out)
+		if !reflect.DeepEqual(expected, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, expected, r)
+		}
+	}
+}
+
+func TestSignatureValidate(t *testing.T) {
+	tests := []struct {
+		in  Verification
+		out error
+	}{
+		{
+			Verification{Signature: util.StrToPtr("https://example.com/config.ign.sig")},
+			nil,
+		},
+		{
+			Verification{Signature: util.StrToPtr("data:,example")},
+			nil,
+		},
+		{
+			Verification{Signature: util.StrToPtr("config.ign.sig")},
+			errors.ErrInvalidScheme,
+		},
+	}
+
+	for i, test := range tests {
+		err := test.in.Validate(path.ContextPath{})
+		expected := report.Report{}
+		expected.AddOnError(path.New("", "signature"), test.out)
+		if !reflect.DeepEqual(expected, err) {
+			t.Errorf("#%d: bad error: want %v, got %v", i, expected, err)
+		}
+	}
+}
@@ -30,6 +30,8 @@ import (
 	_ "github.com/coreos/ignition/v2/internal/exec/stages/mount"
 	_ "github.com/coreos/ignition/v2/internal/exec/stages/umount"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
+	"github.com/coreos/ignition/v2/internal/redact"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 	"github.com/coreos/ignition/v2/internal/util"
@@ -41,6 +43,7 @@ type Flags struct {
 	Root              string
 	IgnoreUnsupported bool
 	Offline           bool
+	CacheDir          string
 }
 
 func inContainer() bool {
@@ -65,6 +68,20 @@ func Run(cfg types.Config, flags Flags, logger *log.Logger) error {
 	if !inContainer() {
 		return errors.New("this tool is not designed to run on a host system; reprovision the machine instead")
 	}
+	return runImpl(cfg, flags, logger, nil)
+}
+
+// Plan returns the operations Run would perform, without performing them.
+// Since nothing is modified, it may be used outside of a container.
+func Plan(cfg types.Config, flags Flags, logger *log.Logger) (plan.Plan, error) {
+	var p plan.Plan
+	err := runImpl(cfg, flags, logger, &p)
+	return p, err
+}
+
+// runImpl applies the config, or adds the operations it would perform to p
+// if p is not nil.
+func runImpl(cfg types.Config, flags Flags, logger *log.Logger, p *plan.Plan) error {
 
 	// make absolute because our code assumes that
 	var err error
@@ -73,8 +90,9 @@ func Run(cfg types.Config, flags Flags, logger *log.Logger) error {
 	}
 
 	fetcher := resource.Fetcher{
-		Logger:  logger,
-		Offline: flags.Offline,
+		Logger:   logger,
+		Offline:  flags.Offline,
+		CacheDir: flags.CacheDir,
 	}
 
 	state := state.State{}
@@ -88,6 +106,12 @@ func Run(cfg types.Config, flags Flags, logger *log.Logger) error {
 	if err != nil {
 		return err
 	}
+	logger.AddSecrets(redact.Secrets(finalCfg)...)
+
+	// there's no platform to query for metadata
+	if exec.UsesTemplates(finalCfg) {
+		state.TemplateVariables = exec.SystemTemplateVariables()
+	}
 
 	// verify upfront if we'll need networking but we're not allowed
 	if flags.Offline {
@@ -111,7 +135,13 @@ func Run(cfg types.Config, flags Flags, logger *log.Logger) error {
 			panic(fmt.Sprintf("stage '%s' invalid", stageName))
 		}
 		stage := stages.Get(stageName).Create(logger, flags.Root, fetcher, &state)
-		if err := stage.Apply(finalCfg, flags.IgnoreUnsupported); err != nil {
+		if p != nil {
+			ops, err := stage.PlanApply(finalCfg, flags.IgnoreUnsupported)
+			if err != nil {
+				return fmt.Errorf("planning stage '%s': %w", stageName, err)
+			}
+			p.Add(stageName, ops...)
+		} else if err := stage.Apply(finalCfg, flags.IgnoreUnsupported); err != nil {
 			return fmt.Errorf("running stage '%s': %w", stageName, err)
 		}
 	}
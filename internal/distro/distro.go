@@ -33,6 +33,9 @@ var (
 	systemRuntimeConfigDir = "/run/ignition"
 	systemLocalConfigDir   = "/etc/ignition"
 	systemConfigDir        = "/usr/lib/ignition"
+	// registry credentials for oci sources, in the format of a container
+	// pull secret
+	ociPullSecretPath = "/etc/ignition/pull-secret.json"
 
 	// Helper programs
 	groupaddCmd  = "groupadd"
@@ -97,6 +100,7 @@ func SystemRuntimeConfigDir() string {
 }
 func SystemLocalConfigDir() string { return fromEnv("SYSTEM_LOCAL_CONFIG_DIR", systemLocalConfigDir) }
 func SystemConfigDir() string      { return fromEnv("SYSTEM_CONFIG_DIR", systemConfigDir) }
+func OCIPullSecretPath() string    { return fromEnv("OCI_PULL_SECRET_PATH", ociPullSecretPath) }
 
 // SystemConfigDirs returns config directories in descending priority order.
 func SystemConfigDirs() []string {
@@ -15,13 +15,15 @@
 package exec
 
 import (
+	"crypto"
 	"crypto/sha512"
 	"encoding/hex"
-	"net/http"
+	"encoding/json"
 	"net/url"
 
 	"github.com/coreos/ignition/v2/config"
 	"github.com/coreos/ignition/v2/config/shared/errors"
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/internal/log"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
@@ -35,6 +37,54 @@ type ConfigFetcher struct {
 	Logger  *log.Logger
 	Fetcher *resource.Fetcher
 	State   *state.State
+
+	// TrustedKeys are the keys that referenced config signatures are
+	// verified against.
+	TrustedKeys []crypto.PublicKey
+	// RequireSignatures requires every referenced config to be signed.
+	RequireSignatures bool
+}
+
+// LoadTrustedKeys fetches the trusted keys from the given signature settings,
+// which must come from a trusted source such as the system base config.
+func (f *ConfigFetcher) LoadTrustedKeys(signatures types.Signatures) error {
+	for _, key := range signatures.TrustedKeys {
+		data, err := f.fetchResource(key)
+		if err != nil {
+			return err
+		}
+		keys, err := util.ParsePublicKeys(data)
+		if err != nil {
+			return err
+		}
+		f.TrustedKeys = append(f.TrustedKeys, keys...)
+	}
+	f.RequireSignatures = cutil.IsTrue(signatures.Required)
+	return nil
+}
+
+// AssertOnlyReferences checks that an unsigned config can't configure the
+// system when signatures are required. It may only contain the ignition
+// section, whose config references will themselves need to be signed. The
+// rest of the ignition section, such as the proxy and TLS settings, still
+// applies, since the platform can't provide a signature for the user config.
+func (f *ConfigFetcher) AssertOnlyReferences(cfg types.Config) error {
+	if !f.RequireSignatures {
+		return nil
+	}
+	// compare the JSON so that empty and omitted fields are equivalent
+	full, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	stripped, err := json.Marshal(types.Config{Ignition: cfg.Ignition})
+	if err != nil {
+		return err
+	}
+	if string(full) != string(stripped) {
+		return util.ErrUnsignedContent
+	}
+	return nil
 }
 
 // RenderConfig evaluates "ignition.config.replace" and "ignition.config.merge"
@@ -53,7 +103,15 @@ func (f *ConfigFetcher) RenderConfig(cfg types.Config) (types.Config, error) {
 
 		// Replace the HTTP client in the fetcher to be configured with the
 		// timeouts of the new config
-		err = f.Fetcher.UpdateHttpTimeoutsAndCAs(newCfg.Ignition.Timeouts, newCfg.Ignition.Security.TLS.CertificateAuthorities, newCfg.Ignition.Proxy)
+		err = f.Fetcher.UpdateHttpTimeoutsAndCAs(newCfg.Ignition.Timeouts, newCfg.Ignition.Retry, newCfg.Ignition.Security.TLS, newCfg.Ignition.Proxy)
+		if err != nil {
+			return types.Config{}, err
+		}
+		err = f.Fetcher.UpdateVault(newCfg.Ignition.Security.Vault)
+		if err != nil {
+			return types.Config{}, err
+		}
+		err = f.Fetcher.UpdateNetwork(newCfg.Ignition.Network)
 		if err != nil {
 			return types.Config{}, err
 		}
@@ -72,7 +130,15 @@ func (f *ConfigFetcher) RenderConfig(cfg types.Config) (types.Config, error) {
 		// been rendered, so we can use the new config's timeouts and CAs when
 		// fetching more configs.
 		cfgForFetcherSettings := latest.Merge(mergedCfg, newCfg)
-		err = f.Fetcher.UpdateHttpTimeoutsAndCAs(cfgForFetcherSettings.Ignition.Timeouts, cfgForFetcherSettings.Ignition.Security.TLS.CertificateAuthorities, cfgForFetcherSettings.Ignition.Proxy)
+		err = f.Fetcher.UpdateHttpTimeoutsAndCAs(cfgForFetcherSettings.Ignition.Timeouts, cfgForFetcherSettings.Ignition.Retry, cfgForFetcherSettings.Ignition.Security.TLS, cfgForFetcherSettings.Ignition.Proxy)
+		if err != nil {
+			return types.Config{}, err
+		}
+		err = f.Fetcher.UpdateVault(cfgForFetcherSettings.Ignition.Security.Vault)
+		if err != nil {
+			return types.Config{}, err
+		}
+		err = f.Fetcher.UpdateNetwork(cfgForFetcherSettings.Ignition.Network)
 		if err != nil {
 			return types.Config{}, err
 		}
@@ -99,21 +165,11 @@ func (f *ConfigFetcher) fetchReferencedConfig(cfgRef types.Resource) (types.Conf
 	if err != nil {
 		return types.Config{}, err
 	}
-	var headers http.Header
-	if len(cfgRef.HTTPHeaders) > 0 {
-		headers, err = cfgRef.HTTPHeaders.Parse()
-		if err != nil {
-			return types.Config{}, err
-		}
-	}
-	compression := ""
-	if cfgRef.Compression != nil {
-		compression = *cfgRef.Compression
+	opts, err := fetchOptions(cfgRef)
+	if err != nil {
+		return types.Config{}, err
 	}
-	rawCfg, err := f.Fetcher.FetchToBuffer(*u, resource.FetchOptions{
-		Headers:     headers,
-		Compression: compression,
-	})
+	rawCfg, err := f.Fetcher.FetchToBuffer(*u, opts)
 	if err != nil {
 		return types.Config{}, err
 	}
@@ -130,6 +186,19 @@ func (f *ConfigFetcher) fetchReferencedConfig(cfgRef types.Resource) (types.Conf
 		return types.Config{}, err
 	}
 
+	if err := f.verifySignature(cfgRef, opts, rawCfg); err != nil {
+		return types.Config{}, err
+	}
+
+	encrypted := cfgRef.Encryption.IsPresent()
+	if encrypted {
+		rawCfg, err = util.Decrypt(cfgRef.Encryption, rawCfg)
+		if err != nil {
+			f.Logger.Crit("failed to decrypt referenced config: %v", err)
+			return types.Config{}, err
+		}
+	}
+
 	cfg, r, err := config.Parse(rawCfg)
 	f.Logger.LogReport(r)
 	if err != nil {
@@ -140,7 +209,93 @@ func (f *ConfigFetcher) fetchReferencedConfig(cfgRef types.Resource) (types.Conf
 		Kind:       "user",
 		Source:     u.Path,
 		Referenced: true,
+		Encrypted:  encrypted,
 	})
 
 	return cfg, nil
 }
+
+// verifySignature fetches the detached signature of a referenced config and
+// verifies it against the trusted keys.
+func (f *ConfigFetcher) verifySignature(cfgRef types.Resource, opts resource.FetchOptions, rawCfg []byte) error {
+	if !cutil.NotEmpty(cfgRef.Verification.Signature) {
+		if f.RequireSignatures {
+			return util.ErrSignatureRequired
+		}
+		return nil
+	}
+	u, err := url.Parse(*cfgRef.Verification.Signature)
+	if err != nil {
+		return err
+	}
+	// the signature is fetched like the config, but it's never compressed
+	sig, err := f.Fetcher.FetchToBuffer(*u, resource.FetchOptions{
+		Headers: opts.Headers,
+		Auth:    opts.Auth,
+	})
+	if err != nil {
+		return err
+	}
+	if err := util.VerifySignature(f.TrustedKeys, rawCfg, sig); err != nil {
+		f.Logger.Crit("failed to verify signature of referenced config: %v", err)
+		return err
+	}
+	f.Logger.Info("verified signature of referenced config")
+	return nil
+}
+
+// fetchResource fetches res, which must have a source, and verifies its
+// hash.
+func (f *ConfigFetcher) fetchResource(res types.Resource) ([]byte, error) {
+	if res.Source == nil {
+		return nil, errors.ErrSourceRequired
+	}
+	u, err := url.Parse(*res.Source)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := fetchOptions(res)
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.Fetcher.FetchToBuffer(*u, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := util.AssertValid(res.Verification, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// fetchOptions returns the options for fetching res. Configs may hold
+// secrets, so they're marked sensitive.
+func fetchOptions(res types.Resource) (resource.FetchOptions, error) {
+	opts := resource.FetchOptions{
+		Auth:      res.Auth,
+		Sensitive: true,
+	}
+	if len(res.HTTPHeaders) > 0 {
+		headers, err := res.HTTPHeaders.Parse()
+		if err != nil {
+			return resource.FetchOptions{}, err
+		}
+		opts.Headers = headers
+	}
+	if res.Compression != nil {
+		opts.Compression = *res.Compression
+	}
+	mirrors, err := resource.ParseMirrors(res.Sources)
+	if err != nil {
+		return resource.FetchOptions{}, err
+	}
+	opts.Mirrors = mirrors
+	transport := util.TransportVerification(res.Verification)
+	if opts.TransportHash, err = util.GetHasher(transport); err != nil {
+		return resource.FetchOptions{}, err
+	}
+	if opts.ExpectedTransportSum, err = util.ExpectedSum(transport); err != nil {
+		return resource.FetchOptions{}, err
+	}
+	return opts, nil
+}
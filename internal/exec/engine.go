@@ -29,9 +29,11 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	executil "github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/platform"
 	"github.com/coreos/ignition/v2/internal/providers/cmdline"
 	"github.com/coreos/ignition/v2/internal/providers/system"
+	"github.com/coreos/ignition/v2/internal/redact"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 
@@ -56,6 +58,7 @@ var (
 // Engine represents the entity that fetches and executes a configuration.
 type Engine struct {
 	ConfigCache    string
+	FetchCacheDir  string
 	FetchTimeout   time.Duration
 	Logger         *log.Logger
 	NeedNet        string
@@ -63,6 +66,15 @@ type Engine struct {
 	PlatformConfig platform.Config
 	Fetcher        *resource.Fetcher
 	State          *state.State
+	// Plan, if set, puts the engine in dry-run mode: stages add the
+	// operations they would perform to it instead of running. Fetch
+	// stages don't write ConfigCache in dry-run mode, so the other stages
+	// plan from a config cached by an earlier fetch stage run normally.
+	Plan *plan.Plan
+
+	// signatures holds the config signature settings from the system base
+	// config. Settings from other configs aren't trusted.
+	signatures types.Signatures
 }
 
 // Run executes the stage of the given name. It returns true if the stage
@@ -72,6 +84,40 @@ func (e Engine) Run(stageName string) error {
 		fmt.Fprintf(os.Stderr, "engine incorrectly configured\n")
 		return errors.ErrEngineConfiguration
 	}
+	started := time.Now()
+	e.startStageReport(stageName, started)
+	err := e.run(stageName)
+	e.finishStageReport(started, err)
+	return err
+}
+
+// startStageReport adds a report for the stage to the state and starts
+// recording the operations and fetches performed into it.
+func (e Engine) startStageReport(stageName string, started time.Time) {
+	recorder := &log.Recorder{}
+	e.Logger.SetRecorder(recorder)
+	e.State.Stages = append(e.State.Stages, state.StageReport{
+		Name:     stageName,
+		Started:  started.Format(time.RFC3339),
+		Outcome:  "running",
+		Recorder: recorder,
+	})
+}
+
+// finishStageReport records the duration and outcome of the stage in the
+// report started by startStageReport.
+func (e Engine) finishStageReport(started time.Time, err error) {
+	e.Logger.SetRecorder(nil)
+	report := &e.State.Stages[len(e.State.Stages)-1]
+	report.DurationSeconds = time.Since(started).Seconds()
+	if err != nil {
+		report.Outcome = "failure"
+	} else {
+		report.Outcome = "success"
+	}
+}
+
+func (e Engine) run(stageName string) error {
 	baseConfig := emptyConfig
 
 	systemBaseConfig, r, err := system.FetchBaseConfig(e.Logger, e.PlatformConfig.Name())
@@ -86,6 +132,7 @@ func (e Engine) Run(stageName string) error {
 			Referenced: false,
 		})
 	}
+	e.signatures = systemBaseConfig.Ignition.Security.Signatures
 
 	// We special-case the fetch-offline stage a bit here: we want to be able
 	// to handle the case where the provider itself requires networking.
@@ -93,6 +140,8 @@ func (e Engine) Run(stageName string) error {
 		e.Fetcher.Offline = true
 	}
 
+	e.Fetcher.CacheDir = e.FetchCacheDir
+
 	// Run the platform config's Init function pre-config fetch
 	// to perform any additional fetcher configuration  e.x.
 	// configuring the S3RegionHint when running on AWS.
@@ -125,7 +174,32 @@ func (e Engine) Run(stageName string) error {
 	defer e.Logger.PopPrefix()
 
 	fullConfig := latest.Merge(baseConfig, latest.Merge(systemBaseConfig, cfg))
-	err = stages.Get(stageName).Create(e.Logger, e.Root, *e.Fetcher, e.State).Run(fullConfig)
+	e.Logger.AddSecrets(redact.Secrets(fullConfig)...)
+
+	// Query the platform while the fetch stages have networking, since
+	// the files stage may not.
+	if strings.HasPrefix(stageName, "fetch") && UsesTemplates(fullConfig) {
+		err = e.collectTemplateVariables()
+		if err == resource.ErrNeedNet && stageName == "fetch-offline" {
+			err = e.signalNeedNet()
+			if err != nil {
+				e.Logger.Crit("failed to signal neednet: %v", err)
+			}
+			return err
+		} else if err != nil {
+			e.Logger.Crit("failed to collect template variables: %v", err)
+			return err
+		}
+	}
+
+	stage := stages.Get(stageName).Create(e.Logger, e.Root, *e.Fetcher, e.State)
+	if e.Plan != nil {
+		var ops []plan.Operation
+		ops, err = stage.Plan(fullConfig)
+		e.Plan.Add(stageName, ops...)
+	} else {
+		err = stage.Run(fullConfig)
+	}
 	if err == resource.ErrNeedNet && stageName == "fetch-offline" {
 		err = e.signalNeedNet()
 		if err != nil {
@@ -136,7 +210,15 @@ func (e Engine) Run(stageName string) error {
 	if err != nil {
 		// e.Logger could be nil
 		fmt.Fprintf(os.Stderr, "%s failed\n", stageName)
-		tmp, jsonerr := json.MarshalIndent(fullConfig, "", "  ")
+		if e.usedEncryptedConfig() {
+			fmt.Fprintf(os.Stderr, "Not printing the full config since it includes decrypted configs\n")
+			return err
+		}
+		redacted, jsonerr := redact.Config(fullConfig)
+		var tmp []byte
+		if jsonerr == nil {
+			tmp, jsonerr = json.MarshalIndent(redacted, "", "  ")
+		}
 		if jsonerr != nil {
 			// Nothing else to do with this error
 			fmt.Fprintf(os.Stderr, "Could not marshal full config: %v\n", jsonerr)
@@ -149,6 +231,17 @@ func (e Engine) Run(stageName string) error {
 	return nil
 }
 
+// usedEncryptedConfig returns whether any of the fetched configs were
+// encrypted, in which case the full config may contain secrets.
+func (e Engine) usedEncryptedConfig() bool {
+	for _, cfg := range e.State.FetchedConfigs {
+		if cfg.Encrypted {
+			return true
+		}
+	}
+	return false
+}
+
 // logStructuredJournalEntry logs information related to
 // a user/base config into the systemd journal log.
 func logStructuredJournalEntry(cfgInfo state.FetchedConfig) error {
@@ -176,7 +269,7 @@ func logStructuredJournalEntry(cfgInfo state.FetchedConfig) error {
 func (e *Engine) acquireConfig(stageName string) (cfg types.Config, err error) {
 	switch {
 	case strings.HasPrefix(stageName, "fetch"):
-		cfg, err = e.acquireProviderConfig()
+		cfg, err = e.acquireProviderConfig(stageName)
 
 		// if we've successfully fetched and cached the configs, log about them
 		if err == nil && journal.Enabled() {
@@ -197,7 +290,12 @@ func (e *Engine) acquireConfig(stageName string) (cfg types.Config, err error) {
 func (e *Engine) acquireCachedConfig() (cfg types.Config, err error) {
 	var b []byte
 	b, err = os.ReadFile(e.ConfigCache)
-	if err != nil {
+	if os.IsNotExist(err) && e.Plan != nil {
+		// Fetch stages don't write the cache in dry-run mode, so later
+		// stages can only be planned after a real fetch stage.
+		e.Logger.Crit("no cached config at %q; in dry-run mode, stages after the fetch stages need the config cached by a fetch stage run without --dry-run", e.ConfigCache)
+		return
+	} else if err != nil {
 		return
 	}
 	if err = json.Unmarshal(b, &cfg); err != nil {
@@ -206,22 +304,32 @@ func (e *Engine) acquireCachedConfig() (cfg types.Config, err error) {
 	}
 	// Create an http client and fetcher with the timeouts from the cached
 	// config
-	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
+	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Retry, cfg.Ignition.Security.TLS, cfg.Ignition.Proxy)
 	if err != nil {
 		e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 		return
 	}
+	err = e.Fetcher.UpdateVault(cfg.Ignition.Security.Vault)
+	if err != nil {
+		e.Logger.Crit("failed to update Vault config for fetcher: %v", err)
+		return
+	}
+	err = e.Fetcher.UpdateNetwork(cfg.Ignition.Network)
+	if err != nil {
+		e.Logger.Crit("failed to update network config for fetcher: %v", err)
+		return
+	}
 	return
 }
 
 // acquireProviderConfig attempts to fetch the configuration from the
 // provider.
-func (e *Engine) acquireProviderConfig() (cfg types.Config, err error) {
+func (e *Engine) acquireProviderConfig(stageName string) (cfg types.Config, err error) {
 	// Create a new http client and fetcher with the timeouts set via the flags,
 	// since we don't have a config with timeout values we can use
 	timeout := int(e.FetchTimeout.Seconds())
 	emptyProxy := types.Proxy{}
-	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(types.Timeouts{HTTPTotal: &timeout}, nil, emptyProxy)
+	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(types.Timeouts{HTTPTotal: &timeout}, types.Retry{}, types.TLS{}, emptyProxy)
 	if err != nil {
 		e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 		return
@@ -244,11 +352,21 @@ func (e *Engine) acquireProviderConfig() (cfg types.Config, err error) {
 
 	// Update the http client to use the timeouts and CAs from the newly fetched
 	// config
-	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
+	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Retry, cfg.Ignition.Security.TLS, cfg.Ignition.Proxy)
 	if err != nil {
 		e.Logger.Crit("failed to update timeouts and CAs for fetcher: %v", err)
 		return
 	}
+	err = e.Fetcher.UpdateVault(cfg.Ignition.Security.Vault)
+	if err != nil {
+		e.Logger.Crit("failed to update Vault config for fetcher: %v", err)
+		return
+	}
+	err = e.Fetcher.UpdateNetwork(cfg.Ignition.Network)
+	if err != nil {
+		e.Logger.Crit("failed to update network config for fetcher: %v", err)
+		return
+	}
 
 	err = e.Fetcher.RewriteCAsWithDataUrls(cfg.Ignition.Security.TLS.CertificateAuthorities)
 	if err != nil {
@@ -256,6 +374,18 @@ func (e *Engine) acquireProviderConfig() (cfg types.Config, err error) {
 		return
 	}
 
+	err = e.Fetcher.RewriteClientCertificateWithDataUrls(&cfg.Ignition.Security.TLS)
+	if err != nil {
+		e.Logger.Crit("error handling client certificate: %v", err)
+		return
+	}
+
+	err = e.Fetcher.RewriteProxyWithDataUrls(&cfg.Ignition.Proxy)
+	if err != nil {
+		e.Logger.Crit("error handling proxy resources: %v", err)
+		return
+	}
+
 	rpt := validate.Validate(cfg, "json")
 	e.Logger.LogReport(rpt)
 	if rpt.IsFatal() {
@@ -264,6 +394,15 @@ func (e *Engine) acquireProviderConfig() (cfg types.Config, err error) {
 		return
 	}
 
+	if e.Plan != nil {
+		e.Plan.Add(stageName, plan.Operation{
+			Kind:        plan.KindConfigCache,
+			Target:      e.ConfigCache,
+			Description: "write the fetched config to the cache",
+		})
+		return
+	}
+
 	// Populate the config cache.
 	b, err := json.Marshal(cfg)
 	if err != nil {
@@ -317,7 +456,15 @@ func (e *Engine) fetchProviderConfig() (types.Config, error) {
 
 	// Replace the HTTP client in the fetcher to be configured with the
 	// timeouts of the config
-	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Security.TLS.CertificateAuthorities, cfg.Ignition.Proxy)
+	err = e.Fetcher.UpdateHttpTimeoutsAndCAs(cfg.Ignition.Timeouts, cfg.Ignition.Retry, cfg.Ignition.Security.TLS, cfg.Ignition.Proxy)
+	if err != nil {
+		return types.Config{}, err
+	}
+	err = e.Fetcher.UpdateVault(cfg.Ignition.Security.Vault)
+	if err != nil {
+		return types.Config{}, err
+	}
+	err = e.Fetcher.UpdateNetwork(cfg.Ignition.Network)
 	if err != nil {
 		return types.Config{}, err
 	}
@@ -327,6 +474,12 @@ func (e *Engine) fetchProviderConfig() (types.Config, error) {
 		Fetcher: e.Fetcher,
 		State:   e.State,
 	}
+	if err := configFetcher.LoadTrustedKeys(e.signatures); err != nil {
+		return types.Config{}, err
+	}
+	if err := configFetcher.AssertOnlyReferences(cfg); err != nil {
+		return types.Config{}, err
+	}
 
 	return configFetcher.RenderConfig(cfg)
 }
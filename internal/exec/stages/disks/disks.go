@@ -30,6 +30,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 	"github.com/coreos/ignition/v2/internal/systemd"
@@ -83,6 +84,23 @@ func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
 	return errors.New("cannot apply disk modifications live")
 }
 
+func (s stage) PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error) {
+	// Apply never modifies anything; it only rejects configs it can't apply
+	return nil, s.Apply(config, ignoreUnsupported)
+}
+
+func (s stage) Plan(config types.Config) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	ops = append(ops, planPartitions(config)...)
+	ops = append(ops, planRaids(config)...)
+	ops = append(ops, planLuks(config)...)
+	fsOps, err := planFilesystems(config)
+	if err != nil {
+		return nil, err
+	}
+	return append(ops, fsOps...), nil
+}
+
 func (s stage) Run(config types.Config) error {
 	// Interacting with disks/partitions/raids/filesystems in general can cause
 	// udev races. If we do not need to  do anything, we also do not need to
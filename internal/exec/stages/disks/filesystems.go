@@ -29,6 +29,7 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/distro"
 	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/plan"
 )
 
 var (
@@ -147,6 +148,52 @@ func (s stage) createFilesystem(fs types.Filesystem) error {
 		return fmt.Errorf("wipefs failed: %v", err)
 	}
 
+	mkfs, args, err := mkfsArgs(fs, devAlias)
+	if err != nil {
+		return err
+	}
+	if mkfs == "" {
+		// The user specifies this format to skip the creation of a filesystem on a block device.
+		return nil
+	}
+
+	if _, err := s.LogCmd(
+		exec.Command(mkfs, args...),
+		"creating %q filesystem on %q",
+		*fs.Format, devAlias,
+	); err != nil {
+		return fmt.Errorf("mkfs failed: %v", err)
+	}
+
+	// udevd registers an IN_CLOSE_WRITE inotify watch on block device
+	// nodes, and synthesizes udev "change" events when the watch fires.
+	// mkfs.btrfs triggers multiple such events, the first of which
+	// occurs while there is no recognizable filesystem on the
+	// partition. Thus, if an existing partition is reformatted as
+	// btrfs while keeping the same filesystem label, there will be a
+	// synthesized uevent that deletes the /dev/disk/by-label symlink
+	// and a second one that restores it. If we didn't account for this,
+	// a systemd unit that depended on the by-label symlink (e.g.
+	// systemd-fsck-root.service) could have the symlink deleted out
+	// from under it.
+	// Trigger a tagged uevent and wait for it because a bare "udevadm
+	// settle" does not guarantee that all changes were processed
+	// because it's conceivable that only the deleting uevent has
+	// already been processed (or none!) while the restoring uevent
+	// is still sitting in the inotify queue. By triggering our own
+	// event and waiting for it we know that udev will have processed
+	// the device changes.
+	// Test case: boot failure in coreos.ignition.*.btrfsroot kola test.
+	if err := s.waitForUdev(devAlias); err != nil {
+		return fmt.Errorf("failed to wait for udev on %q after formatting: %v", devAlias, err)
+	}
+
+	return nil
+}
+
+// mkfsArgs returns the mkfs command and arguments which create fs on
+// devAlias, or an empty command if no filesystem should be created.
+func mkfsArgs(fs types.Filesystem, devAlias string) (string, []string, error) {
 	mkfs := ""
 	args := translateOptionSliceToStringSlice(fs.Options)
 	switch *fs.Format {
@@ -197,45 +244,50 @@ func (s stage) createFilesystem(fs types.Filesystem) error {
 			args = append(args, "-n", *fs.Label)
 		}
 	case "none":
-		// The user specifies this format to skip the creation of a filesystem on a block device.
-		return nil
+		return "", nil, nil
 	default:
-		return fmt.Errorf("unsupported filesystem format: %q", *fs.Format)
+		return "", nil, fmt.Errorf("unsupported filesystem format: %q", *fs.Format)
 	}
 
-	args = append(args, devAlias)
-	if _, err := s.LogCmd(
-		exec.Command(mkfs, args...),
-		"creating %q filesystem on %q",
-		*fs.Format, devAlias,
-	); err != nil {
-		return fmt.Errorf("mkfs failed: %v", err)
-	}
+	return mkfs, append(args, devAlias), nil
+}
 
-	// udevd registers an IN_CLOSE_WRITE inotify watch on block device
-	// nodes, and synthesizes udev "change" events when the watch fires.
-	// mkfs.btrfs triggers multiple such events, the first of which
-	// occurs while there is no recognizable filesystem on the
-	// partition. Thus, if an existing partition is reformatted as
-	// btrfs while keeping the same filesystem label, there will be a
-	// synthesized uevent that deletes the /dev/disk/by-label symlink
-	// and a second one that restores it. If we didn't account for this,
-	// a systemd unit that depended on the by-label symlink (e.g.
-	// systemd-fsck-root.service) could have the symlink deleted out
-	// from under it.
-	// Trigger a tagged uevent and wait for it because a bare "udevadm
-	// settle" does not guarantee that all changes were processed
-	// because it's conceivable that only the deleting uevent has
-	// already been processed (or none!) while the restoring uevent
-	// is still sitting in the inotify queue. By triggering our own
-	// event and waiting for it we know that udev will have processed
-	// the device changes.
-	// Test case: boot failure in coreos.ignition.*.btrfsroot kola test.
-	if err := s.waitForUdev(devAlias); err != nil {
-		return fmt.Errorf("failed to wait for udev on %q after formatting: %v", devAlias, err)
+// planFilesystems returns the operations createFilesystems would perform.
+func planFilesystems(config types.Config) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	for _, fs := range config.Storage.Filesystems {
+		if fs.Format == nil || !fs.IsBlockDevice() {
+			continue
+		}
+		devAlias := util.DeviceAlias(string(fs.Device))
+		wipe := cutil.IsTrue(fs.WipeFilesystem)
+		if wipe {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindWipe,
+				Target:      fs.Device,
+				Description: fmt.Sprintf("wipe filesystem signatures from %q", devAlias),
+				Command:     []string{distro.WipefsCmd(), "-a", devAlias},
+			})
+		}
+		mkfs, args, err := mkfsArgs(fs, devAlias)
+		if err != nil {
+			return nil, err
+		}
+		if mkfs == "" {
+			continue
+		}
+		op := plan.Operation{
+			Kind:        plan.KindFilesystem,
+			Target:      fs.Device,
+			Description: fmt.Sprintf("create %q filesystem on %q", *fs.Format, devAlias),
+			Command:     append([]string{mkfs}, args...),
+		}
+		if !wipe {
+			op.Description += " unless a matching filesystem already exists"
+		}
+		ops = append(ops, op)
 	}
-
-	return nil
+	return ops, nil
 }
 
 // golang--
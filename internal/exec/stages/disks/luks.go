@@ -33,12 +33,17 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/distro"
 	execUtil "github.com/coreos/ignition/v2/internal/exec/util"
-	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 
 	"github.com/vincent-petithory/dataurl"
 )
 
+const (
+	// planKeyFilePath stands in for the temporary key file in dry-run plans
+	planKeyFilePath = "<key-file>"
+)
+
 var (
 	ErrBadVolume = errors.New("volume is not of the correct type")
 	cexRegx      = regexp.MustCompile(`[0-9a-f]{2}\.[0-9a-f]{4}`)
@@ -167,6 +172,8 @@ func (s *stage) createLuks(config types.Config) error {
 					return fmt.Errorf("failed to resolve keyfile %q: %v", f.Path, err)
 				}
 				for _, op := range fetchOps {
+					// never share the key with peers
+					op.FetchOptions.Sensitive = true
 					if err := s.LogOp(
 						func() error {
 							return s.PerformFetch(op)
@@ -243,28 +250,7 @@ func (s *stage) createLuks(config types.Config) error {
 				}
 			}
 
-			args := []string{
-				"luksFormat",
-				"--type", "luks2",
-				"--key-file", keyFilePath,
-			}
-
-			if !util.NilOrEmpty(luks.Label) {
-				args = append(args, "--label", *luks.Label)
-			}
-
-			if !util.NilOrEmpty(luks.UUID) {
-				args = append(args, "--uuid", *luks.UUID)
-			}
-
-			if len(luks.Options) > 0 {
-				// golang's a really great language...
-				for _, option := range luks.Options {
-					args = append(args, string(option))
-				}
-			}
-
-			args = append(args, devAlias)
+			args := luksFormatArgs(luks, keyFilePath)
 
 			// append the zkey specific luksFormat arguments
 			if luks.Cex.IsPresent() {
@@ -442,7 +428,7 @@ func (s *stage) isLuksDevice(device string) (bool, error) {
 			if luks2 {
 				cmd.Args = append(cmd.Args, "--type", "luks2")
 			}
-			cmdLine := log.QuotedCmd(cmd)
+			cmdLine := s.RedactedCmd(cmd)
 			s.Debug("executing: %s", cmdLine)
 
 			if err := cmd.Run(); err != nil {
@@ -527,6 +513,68 @@ func (s *stage) reuseLuksDevice(luks types.Luks, keyFilePath string) error {
 	return nil
 }
 
+// planLuks returns the operations createLuks would perform.
+func planLuks(config types.Config) []plan.Operation {
+	var ops []plan.Operation
+	for _, luks := range config.Storage.Luks {
+		devAlias := execUtil.DeviceAlias(*luks.Device)
+		if util.IsTrue(luks.WipeVolume) {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindWipe,
+				Target:      *luks.Device,
+				Description: fmt.Sprintf("wipe filesystem signatures from %q", devAlias),
+				Command:     []string{distro.WipefsCmd(), "-a", devAlias},
+			})
+		}
+		format := plan.Operation{
+			Kind:        plan.KindLuks,
+			Target:      *luks.Device,
+			Description: fmt.Sprintf("create luks device %q", luks.Name),
+			Command:     append([]string{distro.CryptsetupCmd()}, luksFormatArgs(luks, planKeyFilePath)...),
+		}
+		if !util.IsTrue(luks.WipeVolume) {
+			format.Description += " unless a matching luks device already exists"
+		}
+		ops = append(ops, format, plan.Operation{
+			Kind:        plan.KindLuks,
+			Target:      *luks.Device,
+			Description: fmt.Sprintf("open luks device %q", luks.Name),
+			Command:     append([]string{distro.CryptsetupCmd()}, luksOpenArgs(luks, planKeyFilePath)...),
+		})
+		if luks.Clevis.IsPresent() {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindLuks,
+				Target:      *luks.Device,
+				Description: fmt.Sprintf("bind luks device %q with clevis", luks.Name),
+			})
+		}
+	}
+	return ops
+}
+
+func luksFormatArgs(luks types.Luks, keyFilePath string) []string {
+	args := []string{
+		"luksFormat",
+		"--type", "luks2",
+		"--key-file", keyFilePath,
+	}
+
+	if !util.NilOrEmpty(luks.Label) {
+		args = append(args, "--label", *luks.Label)
+	}
+
+	if !util.NilOrEmpty(luks.UUID) {
+		args = append(args, "--uuid", *luks.UUID)
+	}
+
+	// golang's a really great language...
+	for _, option := range luks.Options {
+		args = append(args, string(option))
+	}
+
+	return append(args, execUtil.DeviceAlias(*luks.Device))
+}
+
 func luksOpenArgs(luks types.Luks, keyFilePath string) []string {
 	ret := []string{
 		"luksOpen",
@@ -36,6 +36,7 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/distro"
 	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/sgdisk"
 	iutil "github.com/coreos/ignition/v2/internal/util"
 )
@@ -75,6 +76,75 @@ func (s stage) createPartitions(config types.Config) error {
 	return nil
 }
 
+// planPartitions returns the operations createPartitions would perform.
+// Partitions are described as specified in the config, since the actual
+// start and size depend on the disk and its existing partition table.
+func planPartitions(config types.Config) []plan.Operation {
+	var ops []plan.Operation
+	for _, dev := range config.Storage.Disks {
+		devAlias := util.DeviceAlias(string(dev.Device))
+		if cutil.IsTrue(dev.WipeTable) {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindPartitionTable,
+				Target:      dev.Device,
+				Description: fmt.Sprintf("wipe partition table on %q", devAlias),
+				Command:     []string{distro.SgdiskCmd(), "--zap-all", devAlias},
+			})
+		}
+		parts := append([]types.Partition(nil), dev.Partitions...)
+		sort.Stable(PartitionList(parts))
+		for _, part := range parts {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindPartition,
+				Target:      dev.Device,
+				Description: describePartition(part, devAlias),
+			})
+		}
+	}
+	return ops
+}
+
+// describePartition returns a human-readable description of the change
+// made for part.
+func describePartition(part types.Partition, devAlias string) string {
+	if cutil.IsFalse(part.ShouldExist) {
+		if cutil.IsTrue(part.WipePartitionEntry) {
+			return fmt.Sprintf("delete partition %d on %q if it exists", part.Number, devAlias)
+		}
+		return fmt.Sprintf("ensure partition %d does not exist on %q", part.Number, devAlias)
+	}
+	number := "next available partition"
+	if part.Number != 0 {
+		number = fmt.Sprintf("partition %d", part.Number)
+	}
+	var details []string
+	if part.Label != nil {
+		details = append(details, fmt.Sprintf("label %q", *part.Label))
+	}
+	if part.StartMiB != nil && *part.StartMiB != 0 {
+		details = append(details, fmt.Sprintf("start %d MiB", *part.StartMiB))
+	}
+	if part.SizeMiB != nil && *part.SizeMiB != 0 {
+		details = append(details, fmt.Sprintf("size %d MiB", *part.SizeMiB))
+	} else {
+		details = append(details, "maximum size")
+	}
+	if cutil.NotEmpty(part.TypeGUID) {
+		details = append(details, fmt.Sprintf("type %s", *part.TypeGUID))
+	}
+	if cutil.NotEmpty(part.GUID) {
+		details = append(details, fmt.Sprintf("GUID %s", *part.GUID))
+	}
+	action := "create"
+	switch {
+	case cutil.IsTrue(part.WipePartitionEntry):
+		action = "create or recreate"
+	case cutil.IsTrue(part.Resize):
+		action = "create or resize"
+	}
+	return fmt.Sprintf("%s %s on %q (%s) unless a matching partition exists", action, number, devAlias, strings.Join(details, ", "))
+}
+
 // partitionMatches determines if the existing partition matches the spec given. See doc/operator notes for what
 // what it means for an existing partition to match the spec. spec must have non-zero Start and Size.
 func partitionMatches(existing util.PartitionInfo, spec sgdisk.Partition) error {
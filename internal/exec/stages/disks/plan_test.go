@@ -0,0 +1,137 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disks
+
+import (
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/distro"
+	"github.com/coreos/ignition/v2/internal/plan"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPartitions(t *testing.T) {
+	config := types.Config{
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{
+					Device:    "/dev/vda",
+					WipeTable: util.BoolToPtr(true),
+					Partitions: []types.Partition{
+						{
+							Label:  util.StrToPtr("root"),
+							Number: 0,
+						},
+						{
+							Label:    util.StrToPtr("boot"),
+							Number:   1,
+							SizeMiB:  util.IntToPtr(512),
+							TypeGUID: util.StrToPtr("c12a7328-f81f-11d2-ba4b-00a0c93ec93b"),
+						},
+						{
+							Number:      4,
+							ShouldExist: util.BoolToPtr(false),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []plan.Operation{
+		{
+			Kind:        plan.KindPartitionTable,
+			Target:      "/dev/vda",
+			Description: `wipe partition table on "/run/ignition/dev_aliases/dev/vda"`,
+			Command:     []string{distro.SgdiskCmd(), "--zap-all", "/run/ignition/dev_aliases/dev/vda"},
+		},
+		{
+			Kind:        plan.KindPartition,
+			Target:      "/dev/vda",
+			Description: `create partition 1 on "/run/ignition/dev_aliases/dev/vda" (label "boot", size 512 MiB, type c12a7328-f81f-11d2-ba4b-00a0c93ec93b) unless a matching partition exists`,
+		},
+		{
+			Kind:        plan.KindPartition,
+			Target:      "/dev/vda",
+			Description: `ensure partition 4 does not exist on "/run/ignition/dev_aliases/dev/vda"`,
+		},
+		{
+			Kind:        plan.KindPartition,
+			Target:      "/dev/vda",
+			Description: `create next available partition on "/run/ignition/dev_aliases/dev/vda" (label "root", maximum size) unless a matching partition exists`,
+		},
+	}, planPartitions(config))
+}
+
+func TestPlanFilesystems(t *testing.T) {
+	config := types.Config{
+		Storage: types.Storage{
+			Filesystems: []types.Filesystem{
+				{
+					Device:         "/dev/vda1",
+					Format:         util.StrToPtr("xfs"),
+					Label:          util.StrToPtr("root"),
+					WipeFilesystem: util.BoolToPtr(true),
+				},
+				{
+					Device: "/dev/vda2",
+					Format: util.StrToPtr("ext4"),
+				},
+				{
+					Device: "/dev/vda3",
+					Format: util.StrToPtr("none"),
+				},
+				{
+					Device: "/dev/vda4",
+				},
+			},
+		},
+	}
+
+	ops, err := planFilesystems(config)
+	assert.NoError(t, err)
+	assert.Equal(t, []plan.Operation{
+		{
+			Kind:        plan.KindWipe,
+			Target:      "/dev/vda1",
+			Description: `wipe filesystem signatures from "/run/ignition/dev_aliases/dev/vda1"`,
+			Command:     []string{distro.WipefsCmd(), "-a", "/run/ignition/dev_aliases/dev/vda1"},
+		},
+		{
+			Kind:        plan.KindFilesystem,
+			Target:      "/dev/vda1",
+			Description: `create "xfs" filesystem on "/run/ignition/dev_aliases/dev/vda1"`,
+			Command:     []string{distro.XfsMkfsCmd(), "-f", "-L", "root", "/run/ignition/dev_aliases/dev/vda1"},
+		},
+		{
+			Kind:        plan.KindFilesystem,
+			Target:      "/dev/vda2",
+			Description: `create "ext4" filesystem on "/run/ignition/dev_aliases/dev/vda2" unless a matching filesystem already exists`,
+			Command:     []string{distro.Ext4MkfsCmd(), "-F", "/run/ignition/dev_aliases/dev/vda2"},
+		},
+	}, ops)
+
+	config.Storage.Filesystems = []types.Filesystem{
+		{
+			Device: "/dev/vda1",
+			Format: util.StrToPtr("bogus"),
+		},
+	}
+	_, err = planFilesystems(config)
+	assert.Error(t, err)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/distro"
 	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/plan"
 )
 
 func (s stage) createRaids(config types.Config) error {
@@ -48,33 +49,8 @@ func (s stage) createRaids(config types.Config) error {
 	}
 
 	for _, md := range config.Storage.Raid {
-		if md.Spares == nil {
-			zero := 0
-			md.Spares = &zero
-		}
-		args := []string{
-			"--create", md.Name,
-			"--force",
-			"--run",
-			"--homehost", "any",
-			"--level", *md.Level,
-			"--raid-devices", fmt.Sprintf("%d", len(md.Devices)-*md.Spares),
-		}
-
-		if *md.Spares > 0 {
-			args = append(args, "--spare-devices", fmt.Sprintf("%d", *md.Spares))
-		}
-
-		for _, o := range md.Options {
-			args = append(args, string(o))
-		}
-
-		for _, dev := range md.Devices {
-			args = append(args, util.DeviceAlias(string(dev)))
-		}
-
 		if _, err := s.LogCmd(
-			exec.Command(distro.MdadmCmd(), args...),
+			exec.Command(distro.MdadmCmd(), mdadmArgs(md)...),
 			"creating %q", md.Name,
 		); err != nil {
 			return fmt.Errorf("mdadm failed: %v", err)
@@ -94,3 +70,45 @@ func (s stage) createRaids(config types.Config) error {
 
 	return nil
 }
+
+// planRaids returns the operations createRaids would perform.
+func planRaids(config types.Config) []plan.Operation {
+	var ops []plan.Operation
+	for _, md := range config.Storage.Raid {
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindRaid,
+			Target:      md.Name,
+			Description: fmt.Sprintf("create %q", md.Name),
+			Command:     append([]string{distro.MdadmCmd()}, mdadmArgs(md)...),
+		})
+	}
+	return ops
+}
+
+func mdadmArgs(md types.Raid) []string {
+	spares := 0
+	if md.Spares != nil {
+		spares = *md.Spares
+	}
+	args := []string{
+		"--create", md.Name,
+		"--force",
+		"--run",
+		"--homehost", "any",
+		"--level", *md.Level,
+		"--raid-devices", fmt.Sprintf("%d", len(md.Devices)-spares),
+	}
+
+	if spares > 0 {
+		args = append(args, "--spare-devices", fmt.Sprintf("%d", spares))
+	}
+
+	for _, o := range md.Options {
+		args = append(args, string(o))
+	}
+
+	for _, dev := range md.Devices {
+		args = append(args, util.DeviceAlias(string(dev)))
+	}
+	return args
+}
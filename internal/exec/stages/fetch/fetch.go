@@ -23,6 +23,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 )
@@ -37,10 +38,11 @@ func init() {
 
 type creator struct{}
 
-func (creator) Create(logger *log.Logger, root string, _ resource.Fetcher, state *state.State) stages.Stage {
+func (creator) Create(logger *log.Logger, root string, f resource.Fetcher, state *state.State) stages.Stage {
 	return &stage{
 		Util: util.Util{
 			DestDir: root,
+			Fetcher: f,
 			Logger:  logger,
 			State:   state,
 		},
@@ -63,8 +65,49 @@ func (s stage) Apply(_ types.Config, _ bool) error {
 	return nil
 }
 
-func (s stage) Run(_ types.Config) error {
-	// Nothing - all we do is fetch and allow anything else in the initramfs to run
+func (s stage) Plan(_ types.Config) ([]plan.Operation, error) {
+	return nil, nil
+}
+
+func (s stage) PlanApply(_ types.Config, _ bool) ([]plan.Operation, error) {
+	return nil, nil
+}
+
+func (s stage) Run(config types.Config) error {
+	// All we do is fetch and allow anything else in the initramfs to run,
+	// but while the network is up, warm the cache for the files stage
+	s.cacheResources(config)
 	s.Info("fetch complete")
 	return nil
 }
+
+// cacheResources fetches the verified contents of files and archives into
+// the fetch cache, if one is configured. Failures are only logged, since
+// the files stage will fetch anything missing.
+func (s stage) cacheResources(config types.Config) {
+	if s.Fetcher.CacheDir == "" {
+		return
+	}
+	cache := func(node types.Node, contents types.Resource) {
+		if contents.Source == nil || (contents.Verification.Hash == nil && len(contents.Verification.Hashes) == 0) {
+			return
+		}
+		op, err := util.NewFetchOp(s.Logger, node, contents)
+		if err != nil {
+			s.Warning("not caching contents of %q: %v", node.Path, err)
+			return
+		}
+		if err := s.Fetcher.FetchToCache(op.Url, op.FetchOptions); err != nil {
+			s.Warning("failed to cache contents of %q: %v", node.Path, err)
+		}
+	}
+	for _, f := range config.Storage.Files {
+		cache(f.Node, f.Contents)
+		for _, a := range f.Append {
+			cache(f.Node, a)
+		}
+	}
+	for _, a := range config.Storage.Archives {
+		cache(a.Node, a.Contents)
+	}
+}
@@ -27,9 +27,9 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	executil "github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
-	"github.com/coreos/ignition/v2/internal/util"
 )
 
 const (
@@ -68,6 +68,14 @@ func (s stage) Apply(_ types.Config, _ bool) error {
 	return nil
 }
 
+func (s stage) Plan(_ types.Config) ([]plan.Operation, error) {
+	return nil, nil
+}
+
+func (s stage) PlanApply(_ types.Config, _ bool) ([]plan.Operation, error) {
+	return nil, nil
+}
+
 func (s stage) Run(cfg types.Config) error {
 	if needsNet, err := configNeedsNet(&cfg); err != nil {
 		return err
@@ -133,9 +141,13 @@ func sourceNeedsNet(res types.Resource) (bool, error) {
 	if res.Source == nil {
 		return false, nil
 	}
-	if u, err := url.Parse(*res.Source); err != nil {
-		return false, err
-	} else {
-		return util.UrlNeedsNet(*u), nil
+	// any of the sources might be needed
+	for _, source := range append([]string{*res.Source}, res.Sources...) {
+		if u, err := url.Parse(source); err != nil {
+			return false, err
+		} else if resource.UrlNeedsNet(*u) {
+			return true, nil
+		}
 	}
+	return false, nil
 }
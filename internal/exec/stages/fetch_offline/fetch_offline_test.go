@@ -130,6 +130,18 @@ func TestConfigNeedsNet(t *testing.T) {
 				},
 			},
 		},
+		// Mirror with URL needs Net
+		{
+			Ignition: types.Ignition{
+				Version: "3.7.0-experimental",
+				Config: types.IgnitionConfig{
+					Replace: types.Resource{
+						Source:  util.StrToPtr("data:,"),
+						Sources: []string{"http://example.com/config.ign"},
+					},
+				},
+			},
+		},
 		// CustomClevis with NeedsNetwork set to true
 		{
 			Storage: types.Storage{
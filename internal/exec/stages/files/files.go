@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/distro"
 	"github.com/coreos/ignition/v2/internal/exec/stages"
@@ -83,6 +84,16 @@ func (s stage) runImpl(config types.Config, isApply bool, applyIgnoreUnsupported
 		}
 	}
 
+	if !isApply && cutil.IsTrue(config.Ignition.Network.Peers.Share) {
+		// !isApply: peers fetch from machines being provisioned
+		stop, err := s.Fetcher.ServePeers(config.Ignition.Network.Peers)
+		if err != nil {
+			s.Warning("not sharing resources with peers: %v", err)
+		} else {
+			defer stop()
+		}
+	}
+
 	// theoretically could support this, but the main user (CoreOS layering)
 	// does not: https://github.com/coreos/rpm-ostree/issues/3435
 	if isApply {
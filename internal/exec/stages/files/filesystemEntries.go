@@ -29,6 +29,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/distro"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/state"
 
 	"github.com/vincent-petithory/dataurl"
 )
@@ -196,14 +197,20 @@ func (s *stage) createResultFile() error {
 		return fmt.Errorf("reading boot ID: %w", err)
 	}
 
+	// A failed stage stops the boot before the files stage writes the
+	// result file, so the recorded outcome is always success.
 	result := struct {
-		ProvisioningBootID string      `json:"provisioningBootID"`
-		ProvisioningDate   string      `json:"provisioningDate"`
-		UserConfigProvided bool        `json:"userConfigProvided"`
-		PreviousReport     interface{} `json:"previousReport,omitempty"`
+		ProvisioningBootID string              `json:"provisioningBootID"`
+		ProvisioningDate   string              `json:"provisioningDate"`
+		UserConfigProvided bool                `json:"userConfigProvided"`
+		Stages             []state.StageReport `json:"stages,omitempty"`
+		Outcome            string              `json:"outcome"`
+		PreviousReport     interface{}         `json:"previousReport,omitempty"`
 	}{
 		ProvisioningBootID: strings.TrimSpace(string(bootIDBytes)),
 		ProvisioningDate:   time.Now().Format(time.RFC3339),
+		Stages:             s.State.Stages,
+		Outcome:            "success",
 		PreviousReport:     prevReport,
 	}
 	for _, config := range s.State.FetchedConfigs {
@@ -247,7 +254,7 @@ func (s *stage) createResultFile() error {
 	return nil
 }
 
-// createFilesystemsEntries creates the files described in config.Storage.{Files,Directories}.
+// createFilesystemsEntries creates the files described in config.Storage.{Files,Directories,Links,Archives}.
 func (s *stage) createFilesystemsEntries(config types.Config) error {
 	s.PushPrefix("createFilesystemsFiles")
 	defer s.PopPrefix()
@@ -279,6 +286,14 @@ func (tmp fileEntry) node() types.Node {
 }
 
 func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
+	return tmp.createWith(l, u, func(_ int, op util.FetchOp) error {
+		return u.PerformFetch(op)
+	})
+}
+
+// createWith creates the file, calling performFetch to write the contents of
+// each of its fetch ops.
+func (tmp fileEntry) createWith(l *log.Logger, u util.Util, performFetch func(int, util.FetchOp) error) error {
 	f := types.File(tmp)
 
 	empty := "" // golang--
@@ -310,14 +325,14 @@ func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
 		return fmt.Errorf("failed to resolve file %q: %v", f.Path, err)
 	}
 
-	for _, op := range fetchOps {
+	for i, op := range fetchOps {
 		msg := "writing file %q"
 		if op.Append {
 			msg = "appending to file %q"
 		}
 		if err := l.LogOp(
 			func() error {
-				return u.PerformFetch(op)
+				return performFetch(i, op)
 			}, msg, f.Path,
 		); err != nil {
 			return fmt.Errorf("failed to create file %q: %v", op.Node.Path, err)
@@ -329,6 +344,29 @@ func (tmp fileEntry) create(l *log.Logger, u util.Util) error {
 	return nil
 }
 
+// unchanged returns the existing file if it has keepUnchanged set and would
+// be replaced with identical contents: it's overwritten with verified
+// contents which the existing file already matches. Otherwise it returns
+// nil. Appended, encrypted, and templated contents aren't compared, since
+// the hash doesn't describe the resulting file.
+func (tmp fileEntry) unchanged() (os.FileInfo, error) {
+	f := types.File(tmp)
+	if !cutil.IsTrue(f.KeepUnchanged) || !cutil.IsTrue(f.Overwrite) || f.Contents.Source == nil || len(f.Append) > 0 ||
+		cutil.IsTrue(f.Template) || f.Contents.Encryption.IsPresent() {
+		return nil, nil
+	}
+	st, err := os.Lstat(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if matches, err := util.FileMatches(f.Path, f.Contents.Verification); err != nil || !matches {
+		return nil, err
+	}
+	return st, nil
+}
+
 type dirEntry types.Directory
 
 func (tmp dirEntry) node() types.Node {
@@ -412,6 +450,41 @@ func (tmp linkEntry) create(l *log.Logger, u util.Util) error {
 	return nil
 }
 
+type archiveEntry types.Archive
+
+func (tmp archiveEntry) node() types.Node {
+	return types.Archive(tmp).Node
+}
+
+func (tmp archiveEntry) create(l *log.Logger, u util.Util) error {
+	a := types.Archive(tmp)
+	st, err := os.Lstat(a.Path)
+	switch {
+	case os.IsNotExist(err):
+		// use default perms, we'll fix it later
+		if err := os.MkdirAll(a.Path, util.DefaultDirectoryPermissions); err != nil {
+			return fmt.Errorf("failed to create directory %s: %v", a.Path, err)
+		}
+	case err != nil:
+		return fmt.Errorf("stat() failed on %s: %v", a.Path, err)
+	case !st.Mode().IsDir():
+		return fmt.Errorf("error extracting archive to %s: A non-directory already exists and overwrite is false", a.Path)
+	}
+
+	if err := u.SetPermissions(a.DirectoryMode, a.Node); err != nil {
+		return fmt.Errorf("error setting directory permissions for %s: %v", a.Path, err)
+	}
+
+	if err := l.LogOp(
+		func() error {
+			return u.ExtractArchive(a)
+		}, "extracting %s archive to %q", util.ArchiveFormat(a), a.Path,
+	); err != nil {
+		return fmt.Errorf("failed to extract archive to %q: %v", a.Path, err)
+	}
+	return nil
+}
+
 // getOrderedCreationList resolves all symlinks in the node paths and sets the path to be
 // prepended by the sysroot. It orders the list from shallowest (e.g. /a) to deepeset
 // (e.g. /a/b/c/d/e).
@@ -447,6 +520,20 @@ func (s stage) getOrderedCreationList(config types.Config) ([]filesystemEntry, e
 		entries = append(entries, fileEntry(f))
 	}
 
+	for _, a := range config.Storage.Archives {
+		path, err := s.JoinPath(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := paths[path]; ok {
+			return nil, fmt.Errorf("archive at %s resolved to %s after symlink chasing, but another entry with path %s also resolves there",
+				a.Path, path, existing)
+		}
+		paths[path] = a.Path
+		a.Path = path
+		entries = append(entries, archiveEntry(a))
+	}
+
 	hardlinks := []filesystemEntry{}
 	for _, l := range config.Storage.Links {
 		path, err := s.JoinPath(l.Path)
@@ -501,10 +588,28 @@ func (s *stage) relabelPath(path string) error {
 }
 
 // createEntries creates any files or directories listed for the filesystem in Storage.{Files,Directories}.
+// Remote file contents are downloaded concurrently, ahead of their files' creation.
 func (s *stage) createEntries(entries []filesystemEntry) error {
 	s.PushPrefix("createFiles")
 	defer s.PopPrefix()
 
+	// files with keepUnchanged which already have the expected contents
+	// aren't fetched
+	unchanged := map[string]os.FileInfo{}
+	for _, e := range entries {
+		if f, ok := e.(fileEntry); ok {
+			st, err := f.unchanged()
+			if err != nil {
+				s.Warning("couldn't compare existing file %q: %v", f.Path, err)
+			} else if st != nil {
+				unchanged[f.Path] = st
+			}
+		}
+	}
+
+	prefetcher := s.startPrefetch(entries, unchanged)
+	defer prefetcher.close()
+
 	for _, e := range entries {
 		path := e.node().Path
 		if !strings.HasPrefix(path, s.DestDir) {
@@ -514,16 +619,38 @@ func (s *stage) createEntries(entries []filesystemEntry) error {
 		if err := s.relabelPath(path); err != nil {
 			return fmt.Errorf("error relabeling paths for %s: %v", path, err)
 		}
+		// an earlier entry may have replaced the file since it was compared
+		if prev, ok := unchanged[path]; ok && sameFile(path, prev) {
+			f := e.(fileEntry)
+			s.Info("file %q already has the expected contents; not rewriting it", path)
+			if err := s.SetPermissions(f.Mode, f.Node); err != nil {
+				return fmt.Errorf("error setting file permissions for %s: %v", path, err)
+			}
+			continue
+		}
 		if err := s.removePathOnOverwrite(e); err != nil {
 			return fmt.Errorf("error removing existing file %s: %v", path, err)
 		}
-		if err := e.create(s.Logger, s.Util); err != nil {
+		var err error
+		if f, ok := e.(fileEntry); ok {
+			err = f.createWith(s.Logger, s.Util, prefetcher.performFetch(s.Util, path))
+		} else {
+			err = e.create(s.Logger, s.Util)
+		}
+		if err != nil {
 			return fmt.Errorf("error creating %s: %v", path, err)
 		}
 	}
 	return nil
 }
 
+// sameFile returns whether path is still the file described by prev, and
+// hasn't been modified.
+func sameFile(path string, prev os.FileInfo) bool {
+	st, err := os.Lstat(path)
+	return err == nil && os.SameFile(st, prev) && st.Size() == prev.Size() && st.ModTime().Equal(prev.ModTime())
+}
+
 // save the cex Volume keys
 func (s *stage) createCexVolumeKeys(config types.Config) error {
 	if len(config.Storage.Luks) == 0 {
@@ -15,7 +15,12 @@
 package files
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 )
 
 func TestBuildCrypttabOptions(t *testing.T) {
@@ -91,3 +96,64 @@ func TestBuildCrypttabOptionsWithClevis(t *testing.T) {
 		})
 	}
 }
+
+func TestFileEntryUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash := "sha256-a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+	file := func(overwrite bool, hash string, modify func(*types.File)) fileEntry {
+		f := types.File{
+			Node: types.Node{Path: path, Overwrite: cutil.BoolToPtr(overwrite)},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.Resource{
+					Source:       cutil.StrToPtr("https://example.com/file"),
+					Verification: types.Verification{Hash: cutil.StrToPtr(hash)},
+				},
+				KeepUnchanged: cutil.BoolToPtr(true),
+			},
+		}
+		if modify != nil {
+			modify(&f)
+		}
+		return fileEntry(f)
+	}
+
+	tests := []struct {
+		name      string
+		in        fileEntry
+		unchanged bool
+	}{
+		{"matching", file(true, hash, nil), true},
+		{"not overwritten", file(false, hash, nil), false},
+		{"not kept", file(true, hash, func(f *types.File) {
+			f.KeepUnchanged = nil
+		}), false},
+		{"different hash", file(true, "sha256-0000000000000000000000000000000000000000000000000000000000000000", nil), false},
+		{"appended", file(true, hash, func(f *types.File) {
+			f.Append = []types.Resource{{Source: cutil.StrToPtr("data:,x")}}
+		}), false},
+		{"templated", file(true, hash, func(f *types.File) {
+			f.Template = cutil.BoolToPtr(true)
+		}), false},
+		{"missing", file(true, hash, func(f *types.File) {
+			f.Path = filepath.Join(filepath.Dir(path), "missing")
+		}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := tt.in.unchanged()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (st != nil) != tt.unchanged {
+				t.Errorf("got unchanged %v, want %v", st != nil, tt.unchanged)
+			}
+			if st != nil && !sameFile(path, st) {
+				t.Error("file reported as modified")
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package files
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
@@ -108,6 +109,17 @@ func (s stage) ensureUsers(config types.Config) error {
 	defer s.PopPrefix()
 
 	for _, u := range config.Passwd.Users {
+		if util.NotEmpty(u.PasswordHashSource.Source) && !util.IsFalse(u.ShouldExist) {
+			hash, err := s.Fetcher.FetchResource(u.PasswordHashSource)
+			if err != nil {
+				return fmt.Errorf("failed to fetch password hash for %q: %v",
+					u.Name, err)
+			}
+			passwordHash := strings.TrimSpace(string(hash))
+			s.AddSecrets(passwordHash)
+			u.PasswordHash = &passwordHash
+		}
+
 		if err := s.EnsureUser(u); err != nil {
 			return fmt.Errorf("failed to create user %q: %v",
 				u.Name, err)
@@ -0,0 +1,317 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/plan"
+	"github.com/coreos/ignition/v2/internal/resource"
+)
+
+func (s stage) Plan(config types.Config) ([]plan.Operation, error) {
+	return s.planImpl(config, false, false)
+}
+
+func (s stage) PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error) {
+	return s.planImpl(config, true, ignoreUnsupported)
+}
+
+// planImpl returns the users, groups, files, directories, links, archives, and units
+// runImpl would create. The contents of remote resources are not fetched;
+// their hash is only reported if the config specifies one.
+func (s stage) planImpl(config types.Config, isApply bool, applyIgnoreUnsupported bool) ([]plan.Operation, error) {
+	var ops []plan.Operation
+
+	if isApply {
+		if !applyIgnoreUnsupported && (len(config.Passwd.Users) > 0 || len(config.Passwd.Groups) > 0) {
+			return nil, errors.New("cannot apply passwd live")
+		}
+	} else {
+		passwdOps, err := s.planPasswd(config)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, passwdOps...)
+	}
+
+	entries, err := s.getOrderedCreationList(config)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		entryOps, err := s.planEntry(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan %s: %v", e.node().Path, err)
+		}
+		ops = append(ops, entryOps...)
+	}
+
+	for _, unit := range config.Systemd.Units {
+		unitOps, err := s.planUnit(unit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan unit %q: %v", unit.Name, err)
+		}
+		ops = append(ops, unitOps...)
+	}
+
+	if !isApply && len(config.Storage.Luks) > 0 {
+		path, err := s.JoinPath("/etc/crypttab")
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindFile,
+			Target:      path,
+			Description: fmt.Sprintf("add %d crypttab entries", len(config.Storage.Luks)),
+		})
+	}
+
+	return ops, nil
+}
+
+func (s stage) planPasswd(config types.Config) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	for _, g := range config.Passwd.Groups {
+		groupOps, err := s.PlanGroup(g)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan group %q: %v", g.Name, err)
+		}
+		ops = append(ops, groupOps...)
+	}
+	for _, u := range config.Passwd.Users {
+		if cutil.NotEmpty(u.PasswordHashSource.Source) && !cutil.IsFalse(u.ShouldExist) {
+			op, err := s.planPasswordHashFetch(u)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan user %q: %v", u.Name, err)
+			}
+			ops = append(ops, op)
+		}
+		userOps, err := s.PlanUser(u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan user %q: %v", u.Name, err)
+		}
+		ops = append(ops, userOps...)
+	}
+	return ops, nil
+}
+
+// planPasswordHashFetch describes fetching the password hash of the user
+// from passwordHashSource. Unlike file contents, data URLs aren't hashed,
+// since the hash would identify the password hash.
+func (s stage) planPasswordHashFetch(u types.PasswdUser) (plan.Operation, error) {
+	source, err := url.Parse(*u.PasswordHashSource.Source)
+	if err != nil {
+		return plan.Operation{}, err
+	}
+	op := plan.Operation{
+		Kind:        plan.KindUser,
+		Target:      u.Name,
+		Description: fmt.Sprintf("fetch the password hash of user %q", u.Name),
+		Source:      resource.RedactedURL(*source),
+	}
+	if hashes := u.PasswordHashSource.Verification; hashes.Hash != nil {
+		op.Hash = *hashes.Hash
+	} else if len(hashes.Hashes) > 0 {
+		op.Hash = hashes.Hashes[0]
+	}
+	return op, nil
+}
+
+func (s stage) planEntry(e filesystemEntry) ([]plan.Operation, error) {
+	overwrite := ""
+	if cutil.IsTrue(e.node().Overwrite) {
+		overwrite = ", replacing any existing node"
+	}
+
+	switch entry := e.(type) {
+	case fileEntry:
+		f := types.File(entry)
+		if f.Contents.Source == nil && len(f.Append) == 0 {
+			return []plan.Operation{{
+				Kind:        plan.KindFile,
+				Target:      f.Path,
+				Description: fmt.Sprintf("create empty file %q unless it exists%s", f.Path, overwrite),
+			}}, nil
+		}
+		if st, err := entry.unchanged(); err == nil && st != nil {
+			return []plan.Operation{{
+				Kind:        plan.KindFile,
+				Target:      f.Path,
+				Description: fmt.Sprintf("leave file %q unchanged; it already has the expected contents", f.Path),
+			}}, nil
+		}
+		template := ""
+		if cutil.IsTrue(f.Template) {
+			template = " from template"
+		}
+		var ops []plan.Operation
+		if f.Contents.Source != nil {
+			op, err := s.planFetch(plan.KindFile, f.Node, f.Contents,
+				fmt.Sprintf("write file %q%s%s", f.Path, template, overwrite))
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+		for _, appendee := range f.Append {
+			op, err := s.planFetch(plan.KindFile, f.Node, appendee,
+				fmt.Sprintf("append to file %q%s", f.Path, template))
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+		return ops, nil
+	case dirEntry:
+		return []plan.Operation{{
+			Kind:        plan.KindDirectory,
+			Target:      entry.Path,
+			Description: fmt.Sprintf("create directory %q unless it exists%s", entry.Path, overwrite),
+		}}, nil
+	case linkEntry:
+		kind := "symlink"
+		if cutil.IsTrue(entry.Hard) {
+			kind = "hard link"
+		}
+		return []plan.Operation{{
+			Kind:        plan.KindLink,
+			Target:      entry.Path,
+			Description: fmt.Sprintf("create %s %q -> %q%s", kind, entry.Path, *entry.Target, overwrite),
+		}}, nil
+	case archiveEntry:
+		a := types.Archive(entry)
+		op, err := s.planFetch(plan.KindArchive, a.Node, a.Contents,
+			fmt.Sprintf("extract %s archive into %q%s", util.ArchiveFormat(a), a.Path, overwrite))
+		if err != nil {
+			return nil, err
+		}
+		return []plan.Operation{op}, nil
+	default:
+		return nil, fmt.Errorf("unknown entry type %T", e)
+	}
+}
+
+func (s stage) planUnit(unit types.Unit) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	for _, dropin := range unit.Dropins {
+		if dropin.Contents == nil {
+			continue
+		}
+		f, err := s.FileFromSystemdUnitDropin(unit, dropin)
+		if err != nil {
+			return nil, err
+		}
+		op, err := s.planFetchOp(plan.KindUnit, f, nil,
+			fmt.Sprintf("write systemd drop-in %q at %q", dropin.Name, f.Node.Path))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	if !cutil.NilOrEmpty(unit.Contents) {
+		f, err := s.FileFromSystemdUnit(unit)
+		if err != nil {
+			return nil, err
+		}
+		op, err := s.planFetchOp(plan.KindUnit, f, nil,
+			fmt.Sprintf("write unit %q at %q", unit.Name, f.Node.Path))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	if unit.Enabled != nil {
+		action := "disable"
+		if *unit.Enabled {
+			action = "enable"
+		}
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindUnit,
+			Target:      unit.Name,
+			Description: fmt.Sprintf("%s unit %q via systemd preset", action, unit.Name),
+		})
+	}
+
+	if unit.Mask != nil {
+		if *unit.Mask {
+			ops = append(ops, plan.Operation{
+				Kind:        plan.KindUnit,
+				Target:      unit.Name,
+				Description: fmt.Sprintf("mask unit %q", unit.Name),
+			})
+		} else {
+			masked, err := s.IsUnitMasked(unit)
+			if err != nil {
+				return nil, err
+			}
+			if masked {
+				ops = append(ops, plan.Operation{
+					Kind:        plan.KindUnit,
+					Target:      unit.Name,
+					Description: fmt.Sprintf("unmask unit %q", unit.Name),
+				})
+			}
+		}
+	}
+
+	return ops, nil
+}
+
+// planFetch describes fetching the resource into the node.
+func (s stage) planFetch(kind string, node types.Node, res types.Resource, description string) (plan.Operation, error) {
+	f, err := util.NewFetchOp(s.Logger, node, res)
+	if err != nil {
+		return plan.Operation{}, err
+	}
+	expectedHash := res.Verification.Hash
+	if expectedHash == nil && len(res.Verification.Hashes) > 0 {
+		expectedHash = &res.Verification.Hashes[0]
+	}
+	return s.planFetchOp(kind, f, expectedHash, description)
+}
+
+// planFetchOp describes the fetch operation. The contents of data URLs are
+// hashed so that changes to inline contents are visible in the plan without
+// including the contents themselves; for other sources the expected hash
+// from the config is used, if any.
+func (s stage) planFetchOp(kind string, f util.FetchOp, expectedHash *string, description string) (plan.Operation, error) {
+	op := plan.Operation{
+		Kind:        kind,
+		Target:      f.Node.Path,
+		Description: description,
+		Source:      resource.RedactedURL(f.Url),
+	}
+	if f.Url.Scheme == "data" {
+		contents, err := s.Fetcher.FetchToBuffer(f.Url, f.FetchOptions)
+		if err != nil {
+			return plan.Operation{}, err
+		}
+		sum := sha512.Sum512(contents)
+		op.Hash = "sha512-" + hex.EncodeToString(sum[:])
+	} else if expectedHash != nil {
+		op.Hash = *expectedHash
+	}
+	return op, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/resource"
+)
+
+// maxParallelFetches is the number of resources downloaded concurrently.
+const maxParallelFetches = 4
+
+// prefetch is the download of a file's resource in the background.
+type prefetch struct {
+	op     util.FetchOp
+	dir    string
+	done   chan struct{}
+	staged *util.StagedFetch
+	err    error
+}
+
+// prefetcher downloads the remote resources of files concurrently, ahead of
+// the creation of the files, which commits them in order.
+type prefetcher struct {
+	// fetches are indexed by the path of the file and the index of the
+	// fetch op. Ops which aren't prefetched are nil.
+	fetches map[string][]*prefetch
+	cancel  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startPrefetch starts downloading the remote resources of the file entries,
+// in order, skipping the unchanged files. The caller must call close when
+// the entries have been created.
+func (s *stage) startPrefetch(entries []filesystemEntry, unchanged map[string]os.FileInfo) *prefetcher {
+	p := &prefetcher{
+		fetches: map[string][]*prefetch{},
+		cancel:  make(chan struct{}),
+	}
+	// entries with overwrite remove their path, and anything below it,
+	// before they're created
+	var removed []string
+	for _, e := range entries {
+		if cutil.IsTrue(e.node().Overwrite) {
+			removed = append(removed, e.node().Path)
+		}
+	}
+	var queue []*prefetch
+	for _, e := range entries {
+		f, ok := e.(fileEntry)
+		if !ok {
+			continue
+		}
+		if _, ok := unchanged[f.Path]; ok {
+			continue
+		}
+		// errors are reported when the file is created
+		ops, err := s.PrepareFetches(s.Logger, types.File(f))
+		if err != nil {
+			continue
+		}
+		dir, err := stagingDir(f.Path, removed)
+		if err != nil {
+			continue
+		}
+		fetches := make([]*prefetch, len(ops))
+		for i, op := range ops {
+			if !resource.UrlNeedsNet(op.Url) {
+				continue
+			}
+			fetches[i] = &prefetch{
+				op:   op,
+				dir:  dir,
+				done: make(chan struct{}),
+			}
+			queue = append(queue, fetches[i])
+		}
+		p.fetches[f.Path] = fetches
+	}
+
+	jobs := make(chan *prefetch)
+	for i := 0; i < min(maxParallelFetches, len(queue)); i++ {
+		// each worker gets its own logger and fetcher, since they aren't
+		// safe for concurrent use
+		logger := s.Logger.Clone()
+		u := s.Util
+		u.Logger = &logger
+		u.Fetcher = s.Fetcher.Clone(&logger)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for pf := range jobs {
+				pf.staged, pf.err = u.StageFetch(pf.op, pf.dir)
+				close(pf.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, pf := range queue {
+			select {
+			case jobs <- pf:
+			case <-p.cancel:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// stagingDir returns the directory in which to stage the contents of the
+// file at path: the deepest existing directory above it, which is likely on
+// the same filesystem, but above any of the removed paths, so that no entry
+// created before the file removes the staged contents. Directories aren't
+// created, since that would be out of order.
+func stagingDir(path string, removed []string) (string, error) {
+	missing, err := util.FindFirstMissingPathComponent(path)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(missing)
+	outermost := ""
+	for _, r := range removed {
+		if (dir == r || strings.HasPrefix(dir, r+"/")) && (outermost == "" || len(r) < len(outermost)) {
+			outermost = r
+		}
+	}
+	if outermost != "" {
+		dir = filepath.Dir(outermost)
+	}
+	return dir, nil
+}
+
+// performFetch returns a function which commits the prefetched resources of
+// the file at path, or fetches them if they weren't prefetched.
+func (p *prefetcher) performFetch(u util.Util, path string) func(int, util.FetchOp) error {
+	return func(i int, op util.FetchOp) error {
+		fetches := p.fetches[path]
+		if i >= len(fetches) || fetches[i] == nil {
+			return u.PerformFetch(op)
+		}
+		pf := fetches[i]
+		<-pf.done
+		if pf.err != nil {
+			return pf.err
+		}
+		if err := util.MkdirForFile(path); err != nil {
+			return err
+		}
+		return pf.staged.Commit()
+	}
+}
+
+// close stops starting downloads, waits for those in progress, and removes
+// any contents which weren't committed.
+func (p *prefetcher) close() {
+	close(p.cancel)
+	p.wg.Wait()
+	for _, fetches := range p.fetches {
+		for _, pf := range fetches {
+			if pf == nil {
+				continue
+			}
+			select {
+			case <-pf.done:
+				if pf.staged != nil {
+					pf.staged.Close()
+				}
+			default:
+				// never started
+			}
+		}
+	}
+}
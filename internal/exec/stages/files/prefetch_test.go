@@ -0,0 +1,274 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/exec/util"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/resource"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestCreateEntriesPrefetch(t *testing.T) {
+	// each response waits for a second request to be in flight, so the
+	// fetches only complete quickly if they run concurrently
+	var (
+		mu       sync.Mutex
+		inFlight int
+		parallel = make(chan struct{})
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight == 2 {
+			close(parallel)
+		}
+		mu.Unlock()
+		select {
+		case <-parallel:
+		case <-time.After(10 * time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	logger := log.New(true)
+	s := stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  &logger,
+			Fetcher: resource.Fetcher{Logger: &logger},
+		},
+	}
+	file := func(path, source string) types.File {
+		return types.File{
+			Node: types.Node{Path: path},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.Resource{Source: cutil.StrToPtr(source)},
+			},
+		}
+	}
+	appended := file("/etc/app/config", "data:,inline%0A")
+	appended.Append = []types.Resource{{Source: cutil.StrToPtr(server.URL + "/appended")}}
+	entries, err := s.getOrderedCreationList(types.Config{
+		Storage: types.Storage{
+			Directories: []types.Directory{{Node: types.Node{Path: "/etc/app"}}},
+			Files: []types.File{
+				file("/opt/app/artifact", server.URL+"/artifact"),
+				appended,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.createEntries(entries))
+
+	data, err := os.ReadFile(filepath.Join(root, "opt/app/artifact"))
+	require.NoError(t, err)
+	assert.Equal(t, "/artifact", string(data))
+	data, err = os.ReadFile(filepath.Join(root, "etc/app/config"))
+	require.NoError(t, err)
+	assert.Equal(t, "inline\n/appended", string(data))
+
+	// no staged contents are left behind
+	for _, dir := range []string{"", "etc", "etc/app", "opt", "opt/app"} {
+		names, err := os.ReadDir(filepath.Join(root, dir))
+		require.NoError(t, err)
+		for _, name := range names {
+			assert.NotRegexp(t, "^tmp", name.Name(), dir)
+		}
+	}
+}
+
+func TestCreateEntriesPrefetchFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("contents"))
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	logger := log.New(true)
+	s := stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  &logger,
+			Fetcher: resource.Fetcher{Logger: &logger},
+		},
+	}
+	var files []types.File
+	for _, name := range []string{"a", "b", "missing", "c", "d"} {
+		files = append(files, types.File{
+			Node: types.Node{Path: "/" + name},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.Resource{Source: cutil.StrToPtr(server.URL + "/" + name)},
+			},
+		})
+	}
+	entries, err := s.getOrderedCreationList(types.Config{
+		Storage: types.Storage{Files: files},
+	})
+	require.NoError(t, err)
+	assert.Error(t, s.createEntries(entries))
+
+	// files before the failure are created in order, and nothing else
+	// is left behind
+	names, err := os.ReadDir(root)
+	require.NoError(t, err)
+	var created []string
+	for _, name := range names {
+		created = append(created, name.Name())
+	}
+	assert.Equal(t, []string{"a", "b"}, created)
+}
+
+func TestCreateEntriesPrefetchSharedClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	logger := log.New(true)
+	s := stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  &logger,
+			Fetcher: resource.Fetcher{Logger: &logger},
+		},
+	}
+	// the workers fetch through the configured client, so run with -race
+	// to check that they don't share its state
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	tlsCfg := types.TLS{
+		CertificateAuthorities: []types.Resource{{Source: cutil.StrToPtr(dataurl.EncodeBytes(serverCA))}},
+	}
+	require.NoError(t, s.Fetcher.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, types.Retry{}, tlsCfg, types.Proxy{}))
+
+	var files []types.File
+	for i := 0; i < 2*maxParallelFetches; i++ {
+		name := fmt.Sprintf("/file%d", i)
+		files = append(files, types.File{
+			Node: types.Node{Path: name},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.Resource{Source: cutil.StrToPtr(server.URL + name)},
+			},
+		})
+	}
+	entries, err := s.getOrderedCreationList(types.Config{
+		Storage: types.Storage{Files: files},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.createEntries(entries))
+
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(root, f.Path))
+		require.NoError(t, err)
+		assert.Equal(t, f.Path, string(data))
+	}
+}
+
+func TestStagingDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc/app/conf.d"), 0755))
+
+	tests := []struct {
+		path    string
+		removed []string
+		out     string
+	}{
+		// the deepest existing directory
+		{"etc/app/conf.d/a.conf", nil, "etc/app/conf.d"},
+		{"etc/app/new/a.conf", nil, "etc/app"},
+		// above directories which are removed
+		{"etc/app/conf.d/a.conf", []string{"etc/app/conf.d"}, "etc/app"},
+		{"etc/app/conf.d/a.conf", []string{"etc/app/conf.d", "etc/app"}, "etc"},
+		{"etc/app/new/a.conf", []string{"etc/app", "etc/app/new"}, "etc"},
+		// but not beside them
+		{"etc/app/conf.d/a.conf", []string{"etc/application", "etc/app/conf.d/a.conf"}, "etc/app/conf.d"},
+	}
+	for i, test := range tests {
+		var removed []string
+		for _, r := range test.removed {
+			removed = append(removed, filepath.Join(root, r))
+		}
+		dir, err := stagingDir(filepath.Join(root, test.path), removed)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, test.out), dir, "#%d", i)
+	}
+}
+
+func TestCreateEntriesPrefetchOverwrittenDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "opt/app"), 0755))
+	logger := log.New(true)
+	s := stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  &logger,
+			Fetcher: resource.Fetcher{Logger: &logger},
+		},
+	}
+	// the directory is replaced before the file is created, so the
+	// contents must be staged outside it
+	entries, err := s.getOrderedCreationList(types.Config{
+		Storage: types.Storage{
+			Directories: []types.Directory{{Node: types.Node{Path: "/opt/app", Overwrite: cutil.BoolToPtr(true)}}},
+			Files: []types.File{{
+				Node: types.Node{Path: "/opt/app/artifact"},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.Resource{Source: cutil.StrToPtr(server.URL + "/artifact")},
+				},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	prefetcher := s.startPrefetch(entries, nil)
+	pf := prefetcher.fetches[filepath.Join(root, "opt/app/artifact")][0]
+	<-pf.done
+	require.NoError(t, pf.err)
+	assert.Equal(t, filepath.Join(root, "opt"), pf.dir)
+	prefetcher.close()
+
+	require.NoError(t, s.createEntries(entries))
+	data, err := os.ReadFile(filepath.Join(root, "opt/app/artifact"))
+	require.NoError(t, err)
+	assert.Equal(t, "/artifact", string(data))
+}
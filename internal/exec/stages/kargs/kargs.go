@@ -24,6 +24,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 )
@@ -73,6 +74,25 @@ func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
 	return errors.New("cannot apply kargs modifications live")
 }
 
+func (s stage) PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error) {
+	// Apply never modifies anything; it only rejects configs it can't apply
+	return nil, s.Apply(config, ignoreUnsupported)
+}
+
+func (s stage) Plan(config types.Config) ([]plan.Operation, error) {
+	if isNoOp(config) {
+		return nil, nil
+	}
+	return []plan.Operation{
+		{
+			Kind:        plan.KindKargs,
+			Target:      "kernel command line",
+			Description: "update kernel arguments",
+			Command:     append([]string{distro.KargsCmd()}, kargsOpts(config)...),
+		},
+	}, nil
+}
+
 func (s stage) Run(config types.Config) error {
 	if isNoOp(config) {
 		return nil
@@ -85,7 +105,8 @@ func (s stage) Run(config types.Config) error {
 	return nil
 }
 
-func (s *stage) addKargs(config types.Config) error {
+// kargsOpts returns the arguments to pass to the kargs helper.
+func kargsOpts(config types.Config) []string {
 	var opts []string
 	for _, arg := range config.KernelArguments.ShouldExist {
 		opts = append(opts, "--should-exist", string(arg))
@@ -93,8 +114,12 @@ func (s *stage) addKargs(config types.Config) error {
 	for _, arg := range config.KernelArguments.ShouldNotExist {
 		opts = append(opts, "--should-not-exist", string(arg))
 	}
+	return opts
+}
+
+func (s *stage) addKargs(config types.Config) error {
 	_, err := s.LogCmd(
-		exec.Command(distro.KargsCmd(), opts...),
+		exec.Command(distro.KargsCmd(), kargsOpts(config)...),
 		"updating kernel arguments")
 	return err
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 )
@@ -76,20 +77,54 @@ func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
 	return errors.New("cannot apply filesystems modifications live")
 }
 
+func (s stage) PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error) {
+	// Apply never modifies anything; it only rejects configs it can't apply
+	return nil, s.Apply(config, ignoreUnsupported)
+}
+
+func (s stage) Plan(config types.Config) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	for _, fs := range mountableFilesystems(config) {
+		path := filepath.Join(s.DestDir, *fs.Path)
+		args := translateOptionSliceToString(fs.MountOptions, ",")
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindMount,
+			Target:      path,
+			Description: fmt.Sprintf("mount %q at %q with type %q and options %q", fs.Device, path, *fs.Format, args),
+			Command:     mountCmd(fs, path).Args,
+		})
+	}
+	return ops, nil
+}
+
 func (s stage) Run(config types.Config) error {
+	for _, fs := range mountableFilesystems(config) {
+		if err := s.mountFs(fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountableFilesystems returns the filesystems which need to be mounted,
+// sorted from shallowest to deepest mount path.
+func mountableFilesystems(config types.Config) []types.Filesystem {
 	fss := []types.Filesystem{}
 	for _, fs := range config.Storage.Filesystems {
+		if fs.Format == nil || *fs.Format == "swap" || *fs.Format == "" || *fs.Format == "none" {
+			continue
+		}
 		if cutil.NotEmpty(fs.Path) {
 			fss = append(fss, fs)
 		}
 	}
 	sort.Slice(fss, func(i, j int) bool { return util.Depth(*fss[i].Path) < util.Depth(*fss[j].Path) })
-	for _, fs := range fss {
-		if err := s.mountFs(fs); err != nil {
-			return err
-		}
-	}
-	return nil
+	return fss
+}
+
+func mountCmd(fs types.Filesystem, path string) *exec.Cmd {
+	args := translateOptionSliceToString(fs.MountOptions, ",")
+	return exec.Command(distro.MountCmd(), "-o", args, "-t", *fs.Format, fs.Device, path)
 }
 
 // checkForNonDirectories returns an error if any element of path is not a directory
@@ -111,10 +146,6 @@ func checkForNonDirectories(path string) error {
 }
 
 func (s stage) mountFs(fs types.Filesystem) error {
-	if fs.Format == nil || *fs.Format == "swap" || *fs.Format == "" || *fs.Format == "none" {
-		return nil
-	}
-
 	// mount paths shouldn't include symlinks or other non-directories so we can use filepath.Join()
 	// instead of s.JoinPath(). Check that the resulting path is composed of only directories.
 	relpath := *fs.Path
@@ -145,8 +176,7 @@ func (s stage) mountFs(fs types.Filesystem) error {
 	}
 
 	args := translateOptionSliceToString(fs.MountOptions, ",")
-	cmd := exec.Command(distro.MountCmd(), "-o", args, "-t", *fs.Format, fs.Device, path)
-	if _, err := s.LogCmd(cmd,
+	if _, err := s.LogCmd(mountCmd(fs, path),
 		"mounting %q at %q with type %q and options %q", fs.Device, path, *fs.Format, args,
 	); err != nil {
 		return err
@@ -17,6 +17,7 @@ package stages
 import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/registry"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
@@ -26,6 +27,10 @@ import (
 type Stage interface {
 	Run(config types.Config) error
 	Apply(config types.Config, ignoreUnsupported bool) error
+	// Plan and PlanApply return the operations Run and Apply would
+	// perform, respectively, without performing them.
+	Plan(config types.Config) ([]plan.Operation, error)
+	PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error)
 	Name() string
 }
 
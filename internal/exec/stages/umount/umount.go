@@ -20,6 +20,7 @@ package umount
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 
 	cutil "github.com/coreos/ignition/v2/config/util"
@@ -27,6 +28,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/exec/util"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/resource"
 	"github.com/coreos/ignition/v2/internal/state"
 
@@ -72,27 +74,54 @@ func (s stage) Apply(config types.Config, ignoreUnsupported bool) error {
 	return errors.New("cannot apply filesystems modifications live")
 }
 
+func (s stage) PlanApply(config types.Config, ignoreUnsupported bool) ([]plan.Operation, error) {
+	// Apply never modifies anything; it only rejects configs it can't apply
+	return nil, s.Apply(config, ignoreUnsupported)
+}
+
+func (s stage) Plan(config types.Config) ([]plan.Operation, error) {
+	var ops []plan.Operation
+	for _, fs := range umountableFilesystems(config) {
+		path, err := s.JoinPath(*fs.Path)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindUmount,
+			Target:      path,
+			Description: fmt.Sprintf("umount %q", path),
+		})
+	}
+	return ops, nil
+}
+
 func (s stage) Run(config types.Config) error {
+	for _, fs := range umountableFilesystems(config) {
+		if err := s.umountFs(fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// umountableFilesystems returns the filesystems which were mounted by the
+// mount stage, sorted from deepest to shallowest mount path.
+func umountableFilesystems(config types.Config) []types.Filesystem {
 	fss := []types.Filesystem{}
 	for _, fs := range config.Storage.Filesystems {
+		if fs.Format == nil || *fs.Format == "swap" || *fs.Format == "" || *fs.Format == "none" {
+			continue
+		}
 		if cutil.NotEmpty(fs.Path) {
 			fss = append(fss, fs)
 		}
 	}
 	// n.b. sorted backwards
 	sort.Slice(fss, func(i, j int) bool { return util.Depth(*fss[j].Path) < util.Depth(*fss[i].Path) })
-	for _, fs := range fss {
-		if err := s.umountFs(fs); err != nil {
-			return err
-		}
-	}
-	return nil
+	return fss
 }
 
 func (s stage) umountFs(fs types.Filesystem) error {
-	if fs.Format == nil || *fs.Format == "swap" || *fs.Format == "" || *fs.Format == "none" {
-		return nil
-	}
 	path, err := s.JoinPath(*fs.Path)
 	if err != nil {
 		return err
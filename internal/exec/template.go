@@ -0,0 +1,114 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/distro"
+)
+
+const procNetRoute = "/proc/net/route"
+
+// UsesTemplates reports whether any file in the config is a template.
+func UsesTemplates(cfg types.Config) bool {
+	for _, f := range cfg.Storage.Files {
+		if cutil.IsTrue(f.Template) {
+			return true
+		}
+	}
+	return false
+}
+
+// SystemTemplateVariables returns the template variables describing the
+// running system. Variables whose value can't be determined are omitted.
+func SystemTemplateVariables() map[string]string {
+	vars := map[string]string{}
+	if hostname, err := os.Hostname(); err == nil {
+		vars["hostname"] = hostname
+	}
+	if bootID, err := os.ReadFile(distro.BootIDPath()); err == nil {
+		vars["boot_id"] = strings.TrimSpace(string(bootID))
+	}
+	if iface := primaryInterface(); iface != nil {
+		if len(iface.HardwareAddr) > 0 {
+			vars["primary_mac"] = iface.HardwareAddr.String()
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				ipnet, ok := addr.(*net.IPNet)
+				if !ok {
+					continue
+				}
+				if ip := ipnet.IP.To4(); ip != nil {
+					if _, ok := vars["primary_ipv4"]; !ok {
+						vars["primary_ipv4"] = ip.String()
+					}
+				} else if ipnet.IP.IsGlobalUnicast() {
+					if _, ok := vars["primary_ipv6"]; !ok {
+						vars["primary_ipv6"] = ipnet.IP.String()
+					}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// primaryInterface returns the interface with the IPv4 default route, or
+// nil if there isn't one.
+func primaryInterface() *net.Interface {
+	f, err := os.Open(procNetRoute)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] != "00000000" {
+			continue
+		}
+		if iface, err := net.InterfaceByName(fields[0]); err == nil {
+			return iface
+		}
+	}
+	return nil
+}
+
+// collectTemplateVariables records the variables available to file
+// templates in the state, so they're available to the files stage.
+func (e *Engine) collectTemplateVariables() error {
+	vars := SystemTemplateVariables()
+	vars["platform"] = e.PlatformConfig.Name()
+	metadata, err := e.PlatformConfig.Metadata(e.Fetcher)
+	if err != nil {
+		return err
+	}
+	for k, v := range metadata {
+		vars[k] = v
+	}
+	e.State.TemplateVariables = vars
+	return nil
+}
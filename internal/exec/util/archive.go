@@ -0,0 +1,350 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+)
+
+const (
+	// maxZipSymlinkLength bounds the size of a zip entry holding a symlink
+	// target.
+	maxZipSymlinkLength = 4096
+
+	permissionBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+)
+
+// ArchiveFormat returns the format of the archive, defaulting to tar.
+func ArchiveFormat(a types.Archive) string {
+	if cutil.NotEmpty(a.Format) {
+		return *a.Format
+	}
+	return "tar"
+}
+
+// ExtractArchive fetches the archive and unpacks it into a.Path, which must
+// already exist as a directory. Extracted nodes are owned by the archive's
+// user and group, or by root if unspecified; ownership recorded in the
+// archive is ignored. Entries may not replace existing nodes other than
+// directories, nor be written through symlinks.
+func (u Util) ExtractArchive(a types.Archive) error {
+	f, err := NewFetchOp(u.Logger, a.Node, a.Contents)
+	if err != nil {
+		return err
+	}
+
+	// Fetch next to the target directory rather than into the initramfs
+	tmp, err := os.CreateTemp(filepath.Dir(a.Path), "tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := u.Fetcher.Fetch(f.Url, tmp, f.FetchOptions); err != nil {
+		u.Crit("Error fetching archive %q: %v", a.Path, err)
+		return err
+	}
+
+	uid, gid, err := u.ResolveNodeUidAndGid(a.Node, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to determine correct uid and gid for %s: %v", a.Path, err)
+	}
+	x := archiveExtractor{
+		root:     a.Path,
+		uid:      uid,
+		gid:      gid,
+		fileMode: a.FileMode,
+		dirMode:  a.DirectoryMode,
+		created:  map[string]struct{}{},
+	}
+
+	switch format := ArchiveFormat(a); format {
+	case "tar":
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return x.extractTar(tmp)
+	case "zip":
+		st, err := tmp.Stat()
+		if err != nil {
+			return err
+		}
+		return x.extractZip(tmp, st.Size())
+	default:
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+type archiveExtractor struct {
+	root     string
+	uid      int
+	gid      int
+	fileMode *int
+	dirMode  *int
+	// paths created from earlier entries, which later entries may replace
+	created map[string]struct{}
+}
+
+func (x *archiveExtractor) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("reading tar archive: %v", err)
+		}
+
+		path, err := x.resolve(hdr.Name)
+		if err != nil {
+			return err
+		}
+		mode := hdr.FileInfo().Mode() & permissionBits
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.createDir(path, mode)
+		case tar.TypeReg:
+			err = x.createFile(path, tr, mode)
+		case tar.TypeSymlink:
+			err = x.createSymlink(path, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.createHardLink(path, hdr.Linkname)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			return fmt.Errorf("archive entry %q has unsupported type %q", hdr.Name, hdr.Typeflag)
+		}
+		if err != nil {
+			return fmt.Errorf("extracting %q: %v", hdr.Name, err)
+		}
+	}
+}
+
+func (x *archiveExtractor) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("reading zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		path, err := x.resolve(f.Name)
+		if err != nil {
+			return err
+		}
+		if err := x.extractZipEntry(f, path); err != nil {
+			return fmt.Errorf("extracting %q: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (x *archiveExtractor) extractZipEntry(f *zip.File, path string) error {
+	mode := f.Mode()
+	switch {
+	case mode.IsDir():
+		return x.createDir(path, mode&permissionBits)
+	case mode.IsRegular():
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rc.Close()
+		}()
+		return x.createFile(path, rc, mode&permissionBits)
+	case mode&os.ModeSymlink != 0:
+		target, err := readZipSymlink(f)
+		if err != nil {
+			return err
+		}
+		return x.createSymlink(path, target)
+	default:
+		return fmt.Errorf("unsupported file type %v", mode.Type())
+	}
+}
+
+// readZipSymlink returns the target of a symlink, which zip stores as the
+// contents of the entry.
+func readZipSymlink(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	target, err := io.ReadAll(io.LimitReader(rc, maxZipSymlinkLength+1))
+	if err != nil {
+		return "", err
+	}
+	if len(target) > maxZipSymlinkLength {
+		return "", fmt.Errorf("symlink target is longer than %d bytes", maxZipSymlinkLength)
+	}
+	return string(target), nil
+}
+
+// resolve returns the path of an archive entry under the root. Entries which
+// would be outside of the root or beneath a symlink are rejected.
+func (x *archiveExtractor) resolve(name string) (string, error) {
+	rel := strings.TrimLeft(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("archive entry %q is outside of %q", name, x.root)
+	}
+	path := filepath.Join(x.root, rel)
+	for dir := filepath.Dir(path); dir != x.root && strings.HasPrefix(dir, x.root); dir = filepath.Dir(dir) {
+		st, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if st.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("archive entry %q is beneath symlink %q", name, dir)
+		}
+	}
+	return path, nil
+}
+
+// claim checks that nothing exists at path. A file or link created from an
+// earlier entry is removed so that the later entry replaces it.
+func (x *archiveExtractor) claim(path string) error {
+	st, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		x.created[path] = struct{}{}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, ok := x.created[path]; !ok {
+		return fmt.Errorf("%q already exists outside of the archive", path)
+	}
+	if st.IsDir() {
+		return fmt.Errorf("%q is a directory created by an earlier archive entry", path)
+	}
+	return os.Remove(path)
+}
+
+// mkdirAll creates any missing directories between the root and path.
+func (x *archiveExtractor) mkdirAll(path string) error {
+	if path == x.root {
+		return nil
+	}
+	st, err := os.Lstat(path)
+	switch {
+	case err == nil && st.IsDir():
+		return nil
+	case err == nil:
+		return fmt.Errorf("%q exists and is not a directory", path)
+	case !os.IsNotExist(err):
+		return err
+	}
+	if err := x.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.Mkdir(path, DefaultDirectoryPermissions); err != nil {
+		return err
+	}
+	x.created[path] = struct{}{}
+	return x.setPermissions(path, x.dirMode, DefaultDirectoryPermissions)
+}
+
+func (x *archiveExtractor) createDir(path string, mode os.FileMode) error {
+	// the root's permissions come from the config
+	if path == x.root {
+		return nil
+	}
+	if err := x.mkdirAll(path); err != nil {
+		return err
+	}
+	return x.setPermissions(path, x.dirMode, mode)
+}
+
+func (x *archiveExtractor) createFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := x.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := x.claim(path); err != nil {
+		return err
+	}
+	// O_EXCL ensures we never write through a symlink
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, DefaultFilePermissions)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return x.setPermissions(path, x.fileMode, mode)
+}
+
+func (x *archiveExtractor) createSymlink(path, target string) error {
+	if err := x.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := x.claim(path); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, path); err != nil {
+		return err
+	}
+	return os.Lchown(path, x.uid, x.gid)
+}
+
+func (x *archiveExtractor) createHardLink(path, target string) error {
+	targetPath, err := x.resolve(target)
+	if err != nil {
+		return err
+	}
+	if err := x.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := x.claim(path); err != nil {
+		return err
+	}
+	return os.Link(targetPath, path)
+}
+
+// setPermissions sets the ownership of path, then its mode: override if
+// specified, otherwise the mode from the archive.
+func (x *archiveExtractor) setPermissions(path string, override *int, mode os.FileMode) error {
+	if err := os.Lchown(path, x.uid, x.gid); err != nil {
+		return fmt.Errorf("failed to change ownership of %s: %v", path, err)
+	}
+	if override != nil {
+		mode = ToFileMode(*override)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to change mode of %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testArchiveEntry struct {
+	name     string
+	typeflag byte
+	mode     int64
+	contents string
+	linkname string
+}
+
+func makeTar(t *testing.T, entries []testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Mode:     e.mode,
+			Size:     int64(len(e.contents)),
+			Linkname: e.linkname,
+		}))
+		_, err := tw.Write([]byte(e.contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func newTestExtractor(t *testing.T) *archiveExtractor {
+	return &archiveExtractor{
+		root:    t.TempDir(),
+		uid:     os.Getuid(),
+		gid:     os.Getgid(),
+		created: map[string]struct{}{},
+	}
+}
+
+func TestExtractTar(t *testing.T) {
+	x := newTestExtractor(t)
+	x.dirMode = cutil.IntToPtr(0700)
+	archive := makeTar(t, []testArchiveEntry{
+		{name: "./", typeflag: tar.TypeDir, mode: 0755},
+		{name: "bin/", typeflag: tar.TypeDir, mode: 0755},
+		{name: "bin/tool", typeflag: tar.TypeReg, mode: 0755, contents: "#!/bin/sh\n"},
+		{name: "share/doc/README", typeflag: tar.TypeReg, mode: 0644, contents: "readme"},
+		{name: "share/doc/README", typeflag: tar.TypeReg, mode: 0600, contents: "replaced"},
+		{name: "latest", typeflag: tar.TypeSymlink, linkname: "bin/tool"},
+		{name: "tool", typeflag: tar.TypeLink, linkname: "bin/tool"},
+	})
+	require.NoError(t, x.extractTar(bytes.NewReader(archive)))
+
+	st, err := os.Stat(filepath.Join(x.root, "bin"))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeDir|0700, st.Mode())
+	st, err = os.Stat(filepath.Join(x.root, "share"))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeDir|0700, st.Mode())
+
+	st, err = os.Stat(filepath.Join(x.root, "bin/tool"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), st.Mode())
+	data, err := os.ReadFile(filepath.Join(x.root, "share/doc/README"))
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", string(data))
+
+	target, err := os.Readlink(filepath.Join(x.root, "latest"))
+	require.NoError(t, err)
+	assert.Equal(t, "bin/tool", target)
+	hard, err := os.Stat(filepath.Join(x.root, "tool"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(st, hard))
+}
+
+func TestExtractTarRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+		err     string
+	}{
+		{
+			name: "parent traversal",
+			entries: []testArchiveEntry{
+				{name: "../escape", typeflag: tar.TypeReg, mode: 0644},
+			},
+		},
+		{
+			name: "through symlink",
+			entries: []testArchiveEntry{
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"},
+				{name: "link/passwd", typeflag: tar.TypeReg, mode: 0644},
+			},
+		},
+		{
+			name: "hard link outside root",
+			entries: []testArchiveEntry{
+				{name: "passwd", typeflag: tar.TypeLink, linkname: "../../etc/passwd"},
+			},
+		},
+		{
+			name: "existing file",
+			entries: []testArchiveEntry{
+				{name: "existing", typeflag: tar.TypeReg, mode: 0644},
+			},
+			err: "already exists outside of the archive",
+		},
+		{
+			name: "file replacing directory",
+			entries: []testArchiveEntry{
+				{name: "dir", typeflag: tar.TypeDir, mode: 0755},
+				{name: "dir", typeflag: tar.TypeReg, mode: 0644},
+			},
+			err: "is a directory created by an earlier archive entry",
+		},
+		{
+			name: "device",
+			entries: []testArchiveEntry{
+				{name: "null", typeflag: tar.TypeChar, mode: 0666},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x := newTestExtractor(t)
+			require.NoError(t, os.WriteFile(filepath.Join(x.root, "existing"), nil, 0644))
+			err := x.extractTar(bytes.NewReader(makeTar(t, test.entries)))
+			assert.Error(t, err)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	dir := &zip.FileHeader{Name: "etc/"}
+	dir.SetMode(os.ModeDir | 0750)
+	_, err := zw.CreateHeader(dir)
+	require.NoError(t, err)
+	file := &zip.FileHeader{Name: "etc/app.conf"}
+	file.SetMode(0640)
+	w, err := zw.CreateHeader(file)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("key=value\n"))
+	require.NoError(t, err)
+	link := &zip.FileHeader{Name: "app.conf"}
+	link.SetMode(os.ModeSymlink | 0777)
+	w, err = zw.CreateHeader(link)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("etc/app.conf"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	x := newTestExtractor(t)
+	x.fileMode = cutil.IntToPtr(0600)
+	require.NoError(t, x.extractZip(bytes.NewReader(buf.Bytes()), int64(buf.Len())))
+
+	st, err := os.Stat(filepath.Join(x.root, "etc"))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeDir|0750, st.Mode())
+	st, err = os.Stat(filepath.Join(x.root, "etc/app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), st.Mode())
+	data, err := os.ReadFile(filepath.Join(x.root, "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "key=value\n", string(data))
+}
@@ -15,7 +15,8 @@
 package util
 
 import (
-	"encoding/hex"
+	"bytes"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
@@ -46,11 +47,16 @@ type FetchOp struct {
 	FetchOptions resource.FetchOptions
 	Append       bool
 	Node         types.Node
+	// Variables, if not nil, are substituted into the fetched contents,
+	// which are treated as a template.
+	Variables map[string]string
+	// Encryption, if not nil, describes how to decrypt the fetched
+	// contents.
+	Encryption *types.Encryption
 }
 
-func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchOp, error) {
-	var expectedSum []byte
-
+// NewFetchOp converts the resource into a FetchOp writing to the node.
+func NewFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchOp, error) {
 	uri, err := url.Parse(*contents.Source)
 	if err != nil {
 		return FetchOp{}, err
@@ -62,16 +68,24 @@ func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchO
 		return FetchOp{}, err
 	}
 
-	if hasher != nil {
-		// explicitly ignoring the error here because the config should already
-		// be validated by this point
-		_, expectedSumString, _ := util.HashParts(contents.Verification)
-		expectedSum, err = hex.DecodeString(expectedSumString)
-		if err != nil {
-			l.Crit("Error parsing verification string %q: %v", expectedSumString, err)
-			return FetchOp{}, err
-		}
+	expectedSum, err := util.ExpectedSum(contents.Verification)
+	if err != nil {
+		l.Crit("Error verifying file %q: %v", node.Path, err)
+		return FetchOp{}, err
+	}
+
+	transport := util.TransportVerification(contents.Verification)
+	transportHasher, err := util.GetHasher(transport)
+	if err != nil {
+		l.Crit("Error verifying file %q: %v", node.Path, err)
+		return FetchOp{}, err
+	}
+	expectedTransportSum, err := util.ExpectedSum(transport)
+	if err != nil {
+		l.Crit("Error verifying file %q: %v", node.Path, err)
+		return FetchOp{}, err
 	}
+
 	compression := ""
 	if contents.Compression != nil {
 		compression = *contents.Compression
@@ -85,6 +99,16 @@ func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchO
 		}
 	}
 
+	mirrors, err := resource.ParseMirrors(contents.Sources)
+	if err != nil {
+		return FetchOp{}, err
+	}
+
+	var encryption *types.Encryption
+	if contents.Encryption.IsPresent() {
+		encryption = &contents.Encryption
+	}
+
 	return FetchOp{
 		Hash: hasher,
 		Node: node,
@@ -94,7 +118,13 @@ func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchO
 			Compression: compression,
 			ExpectedSum: expectedSum,
 			Headers:     headers,
+			Auth:        contents.Auth,
+			Mirrors:     mirrors,
+
+			TransportHash:        transportHasher,
+			ExpectedTransportSum: expectedTransportSum,
 		},
+		Encryption: encryption,
 	}, nil
 }
 
@@ -105,19 +135,29 @@ func newFetchOp(l *log.Logger, node types.Node, contents types.Resource) (FetchO
 func (u Util) PrepareFetches(l *log.Logger, f types.File) ([]FetchOp, error) {
 	ops := []FetchOp{}
 
+	var variables map[string]string
+	if cutil.IsTrue(f.Template) {
+		variables = map[string]string{}
+		if u.State != nil && u.State.TemplateVariables != nil {
+			variables = u.State.TemplateVariables
+		}
+	}
+
 	if f.Contents.Source != nil {
-		if base, err := newFetchOp(l, f.Node, f.Contents); err != nil {
+		if base, err := NewFetchOp(l, f.Node, f.Contents); err != nil {
 			return nil, err
 		} else {
+			base.Variables = variables
 			ops = append(ops, base)
 		}
 	}
 
 	for _, appendee := range f.Append {
-		if op, err := newFetchOp(l, f.Node, appendee); err != nil {
+		if op, err := NewFetchOp(l, f.Node, appendee); err != nil {
 			return nil, err
 		} else {
 			op.Append = true
+			op.Variables = variables
 			ops = append(ops, op)
 		}
 	}
@@ -196,75 +236,196 @@ func ToFileMode(m int) os.FileMode {
 // PerformFetch performs a fetch operation generated by PrepareFetch, retrieving
 // the file and writing it to disk. Any encountered errors are returned.
 func (u Util) PerformFetch(f FetchOp) error {
-	path := f.Node.Path
-
-	if err := MkdirForFile(path); err != nil {
+	if err := MkdirForFile(f.Node.Path); err != nil {
 		return err
 	}
 
-	// Create a temporary file in the same directory to ensure it's on the same filesystem
-	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp")
+	// Stage in the same directory to ensure it's on the same filesystem
+	staged, err := u.StageFetch(f, filepath.Dir(f.Node.Path))
 	if err != nil {
 		return err
 	}
+	defer staged.Close()
+
+	return staged.Commit()
+}
+
+// FileMatches returns whether path is a regular file whose contents match
+// the hashes of verify. It returns false if verify has no hashes or the file
+// doesn't exist.
+func FileMatches(path string, verify types.Verification) (bool, error) {
+	hasher, err := util.GetHasher(verify)
+	if err != nil || hasher == nil {
+		return false, err
+	}
+	expectedSum, err := util.ExpectedSum(verify)
+	if err != nil {
+		return false, err
+	}
+	file, err := os.OpenFile(path, os.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK, 0)
+	if os.IsNotExist(err) || errors.Is(err, unix.ELOOP) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
 	defer func() {
-		_ = tmp.Close()
+		_ = file.Close()
 	}()
+	if st, err := file.Stat(); err != nil {
+		return false, err
+	} else if !st.Mode().IsRegular() {
+		return false, nil
+	}
+	if _, err := io.Copy(hasher, file); err != nil {
+		return false, err
+	}
+	return bytes.Equal(hasher.Sum(nil), expectedSum), nil
+}
 
-	// os.CreateTemp defaults to 0600
-	if err = tmp.Chmod(DefaultFilePermissions); err != nil {
-		return err
+// StagedFetch holds the contents retrieved by a FetchOp in a temporary file
+// until they're committed to the node.
+type StagedFetch struct {
+	op        FetchOp
+	tmp       *os.File
+	committed bool
+}
+
+// StageFetch retrieves the resource of a FetchOp into a temporary file in
+// dir, decrypting it and rendering it as a template as needed. dir should be
+// on the same filesystem as the node, so that committing the contents is a
+// rename. The caller must Close the result.
+func (u Util) StageFetch(f FetchOp, dir string) (*StagedFetch, error) {
+	path := f.Node.Path
+
+	tmp, err := os.CreateTemp(dir, "tmp")
+	if err != nil {
+		return nil, err
+	}
+	staged := &StagedFetch{
+		op:  f,
+		tmp: tmp,
 	}
 
-	// sometimes the following line will fail (the file might be renamed),
-	// but that's ok (we wanted to keep the file in that case).
-	defer func() {
-		_ = os.Remove(tmp.Name())
-	}()
+	// os.CreateTemp defaults to 0600, which we keep for decrypted contents
+	// until the caller sets the final permissions
+	if f.Encryption == nil {
+		if err = tmp.Chmod(DefaultFilePermissions); err != nil {
+			staged.Close()
+			return nil, err
+		}
+	}
 
 	err = u.Fetcher.Fetch(f.Url, tmp, f.FetchOptions)
 	if err != nil {
 		u.Crit("Error fetching file %q: %v", path, err)
-		return err
+		staged.Close()
+		return nil, err
 	}
 
-	if f.Append {
-		// Make sure that we're appending to a file
-		finfo, err := os.Lstat(path)
-		switch {
-		case os.IsNotExist(err):
-			// No problem, we'll create it.
-			break
-		case err != nil:
-			return err
-		default:
-			if !finfo.Mode().IsRegular() {
-				return fmt.Errorf("can only append to files: %q", path)
-			}
+	if f.Encryption != nil {
+		if err := decryptFile(tmp, *f.Encryption); err != nil {
+			u.Crit("Error decrypting file %q: %v", path, err)
+			staged.Close()
+			return nil, err
 		}
+	}
 
-		// Open with the default permissions, we'll chown/chmod it later
-		targetFile, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, DefaultFilePermissions)
-		if err != nil {
-			return err
+	if f.Variables != nil {
+		if err := renderTemplateFile(tmp, f.Variables); err != nil {
+			staged.Close()
+			return nil, fmt.Errorf("rendering template for %q: %v", path, err)
 		}
-		defer func() {
-			_ = targetFile.Close()
-		}()
+	}
 
-		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
-			return err
-		}
-		if _, err = io.Copy(targetFile, tmp); err != nil {
+	return staged, nil
+}
+
+// Commit writes the staged contents to the node, replacing it or appending
+// to it. The directory of the node must exist.
+func (s *StagedFetch) Commit() error {
+	path := s.op.Node.Path
+
+	if !s.op.Append {
+		err := os.Rename(s.tmp.Name(), path)
+		if errors.Is(err, syscall.EXDEV) || errors.Is(err, os.ErrNotExist) {
+			// The staged file is on another filesystem, or was removed
+			// along with its directory; copy it from the open file.
+			return s.copyTo(path)
+		} else if err != nil {
 			return err
 		}
-	} else {
-		if err = os.Rename(tmp.Name(), path); err != nil {
-			return err
+		s.committed = true
+		return nil
+	}
+
+	// Make sure that we're appending to a file
+	finfo, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		// No problem, we'll create it.
+		break
+	case err != nil:
+		return err
+	default:
+		if !finfo.Mode().IsRegular() {
+			return fmt.Errorf("can only append to files: %q", path)
 		}
 	}
 
-	return nil
+	// Open with the default permissions, we'll chown/chmod it later
+	targetFile, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, DefaultFilePermissions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = targetFile.Close()
+	}()
+
+	if _, err = s.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(targetFile, s.tmp)
+	return err
+}
+
+// copyTo replaces path with a copy of the staged contents.
+func (s *StagedFetch) copyTo(path string) error {
+	st, err := s.tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := s.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		// fails harmlessly if the file was renamed
+		_ = os.Remove(tmp.Name())
+	}()
+	if err := tmp.Chmod(st.Mode().Perm()); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, s.tmp); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close releases the staged contents, removing them if they weren't
+// committed by a rename.
+func (s *StagedFetch) Close() {
+	_ = s.tmp.Close()
+	if !s.committed {
+		_ = os.Remove(s.tmp.Name())
+	}
 }
 
 // MkdirForFile helper creates the directory components of path.
@@ -389,3 +550,23 @@ func (u Util) getGroupID(name string) (int, error) {
 	}
 	return int(gid), nil
 }
+
+// decryptFile decrypts the contents of f, replacing them with the plaintext.
+func decryptFile(f *os.File, encryption types.Encryption) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	ciphertext, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	plaintext, err := util.Decrypt(encryption, ciphertext)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = f.WriteAt(plaintext, 0)
+	return err
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/resource"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagedFetchCommit(t *testing.T) {
+	logger := log.New(true)
+	u := Util{
+		Logger:  &logger,
+		Fetcher: resource.Fetcher{Logger: &logger},
+	}
+	source, err := url.Parse("data:,hello")
+	require.NoError(t, err)
+	root := t.TempDir()
+	path := filepath.Join(root, "dest", "file")
+	require.NoError(t, MkdirForFile(path))
+
+	// committing renames the staged file
+	staged, err := u.StageFetch(FetchOp{Url: *source, Node: types.Node{Path: path}}, root)
+	require.NoError(t, err)
+	require.NoError(t, staged.Commit())
+	staged.Close()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// the staged file is copied if its directory was removed
+	stagingDir := filepath.Join(root, "staging")
+	require.NoError(t, os.Mkdir(stagingDir, 0755))
+	staged, err = u.StageFetch(FetchOp{Url: *source, Node: types.Node{Path: path}}, stagingDir)
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(stagingDir))
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, staged.Commit())
+	staged.Close()
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	st, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultFilePermissions, st.Mode())
+
+	// nothing is left behind
+	names, err := os.ReadDir(filepath.Join(root, "dest"))
+	require.NoError(t, err)
+	assert.Len(t, names, 1)
+}
+
+func TestFileMatches(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello world\n"), 0644))
+	hash := "sha256-a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+	wrong := "sha256-0000000000000000000000000000000000000000000000000000000000000000"
+
+	matches, err := FileMatches(path, types.Verification{Hash: &hash})
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = FileMatches(path, types.Verification{Hash: &wrong})
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	// without a hash, nothing is known to match
+	matches, err = FileMatches(path, types.Verification{})
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	// missing files and symlinks don't match
+	matches, err = FileMatches(filepath.Join(root, "missing"), types.Verification{Hash: &hash})
+	require.NoError(t, err)
+	assert.False(t, matches)
+	require.NoError(t, os.Symlink(path, filepath.Join(root, "link")))
+	matches, err = FileMatches(filepath.Join(root, "link"), types.Verification{Hash: &hash})
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
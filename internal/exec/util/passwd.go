@@ -28,6 +28,7 @@ import (
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/as_user"
 	"github.com/coreos/ignition/v2/internal/distro"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"golang.org/x/sys/unix"
 )
 
@@ -56,24 +57,40 @@ func appendIfStringSet(args []string, arg string, str *string) []string {
 // If the `shouldExist` field is set to false and the user already exists, then
 // they will be deleted.
 func (u Util) EnsureUser(c types.PasswdUser) error {
-	shouldExist := !util.IsFalse(c.ShouldExist)
 	exists, err := u.CheckIfUserExists(c)
 	if err != nil {
 		return err
 	}
-	if !shouldExist {
-		if exists {
-			args := []string{"--remove", "--root", u.DestDir, c.Name}
-			_, err := u.LogCmd(exec.Command(distro.UserdelCmd(), args...),
-				"deleting user %q", c.Name)
-			if err != nil {
-				return fmt.Errorf("failed to delete user %q: %v",
-					c.Name, err)
-			}
+	cmd, args := u.userCmd(c, exists)
+	if cmd == "" {
+		return nil
+	}
+	if util.IsFalse(c.ShouldExist) {
+		_, err := u.LogCmd(exec.Command(cmd, args...),
+			"deleting user %q", c.Name)
+		if err != nil {
+			return fmt.Errorf("failed to delete user %q: %v",
+				c.Name, err)
 		}
 		return nil
 	}
 
+	_, err = u.LogCmd(exec.Command(cmd, args...),
+		"creating or modifying user %q", c.Name)
+	return err
+}
+
+// userCmd returns the command and arguments EnsureUser runs for the user,
+// given whether it already exists. The command is empty if nothing needs to
+// be done.
+func (u Util) userCmd(c types.PasswdUser, exists bool) (string, []string) {
+	if util.IsFalse(c.ShouldExist) {
+		if exists {
+			return distro.UserdelCmd(), []string{"--remove", "--root", u.DestDir, c.Name}
+		}
+		return "", nil
+	}
+
 	args := []string{"--root", u.DestDir}
 
 	var cmd string
@@ -125,11 +142,57 @@ func (u Util) EnsureUser(c types.PasswdUser) error {
 
 	args = appendIfStringSet(args, "--shell", c.Shell)
 
-	args = append(args, c.Name)
+	return cmd, append(args, c.Name)
+}
 
-	_, err = u.LogCmd(exec.Command(cmd, args...),
-		"creating or modifying user %q", c.Name)
-	return err
+// PlanUser returns the operations EnsureUser, AuthorizeSSHKeys, and
+// SetPasswordHash would perform for the user. Password hashes are not
+// included in the plan.
+func (u Util) PlanUser(c types.PasswdUser) ([]plan.Operation, error) {
+	exists, err := u.CheckIfUserExists(c)
+	if err != nil {
+		return nil, err
+	}
+	cmd, args := u.userCmd(c, exists)
+	if cmd == "" {
+		return nil, nil
+	}
+	description := fmt.Sprintf("create user %q", c.Name)
+	switch {
+	case util.IsFalse(c.ShouldExist):
+		description = fmt.Sprintf("delete user %q", c.Name)
+	case exists:
+		description = fmt.Sprintf("modify user %q", c.Name)
+	}
+	ops := []plan.Operation{{
+		Kind:        plan.KindUser,
+		Target:      c.Name,
+		Description: description,
+		Command:     append([]string{cmd}, redactPasswordArgs(args)...),
+	}}
+	if util.IsFalse(c.ShouldExist) {
+		return ops, nil
+	}
+	if len(c.SSHAuthorizedKeys) > 0 {
+		ops = append(ops, plan.Operation{
+			Kind:        plan.KindUser,
+			Target:      c.Name,
+			Description: fmt.Sprintf("add %d SSH key(s) to user %q", len(c.SSHAuthorizedKeys), c.Name),
+		})
+	}
+	return ops, nil
+}
+
+// redactPasswordArgs returns a copy of args with the value of any
+// --password argument replaced.
+func redactPasswordArgs(args []string) []string {
+	ret := append([]string(nil), args...)
+	for i := 0; i < len(ret)-1; i++ {
+		if ret[i] == "--password" && ret[i+1] != "*" {
+			ret[i+1] = "<redacted>"
+		}
+	}
+	return ret
 }
 
 // GetUserHomeDir returns the user home directory. Note that DestDir is not
@@ -312,22 +375,42 @@ func (u Util) SetPasswordHash(c types.PasswdUser) error {
 // `shouldExist` field is set to false and the group already exists,
 // then it will be deleted.
 func (u Util) EnsureGroup(g types.PasswdGroup) error {
-	shouldExist := !util.IsFalse(g.ShouldExist)
 	exists, err := u.CheckIfGroupExists(g)
 	if err != nil {
 		return err
 	}
-	if !shouldExist {
-		if exists {
-			args := []string{"--root", u.DestDir, g.Name}
-			_, err := u.LogCmd(exec.Command(distro.GroupdelCmd(), args...),
-				"deleting group %q", g.Name)
-			if err != nil {
-				return fmt.Errorf("failed to delete group %q: %v",
-					g.Name, err)
-			}
+	cmd, args := u.groupCmd(g, exists)
+	switch {
+	case cmd == "":
+		return nil
+	case util.IsFalse(g.ShouldExist):
+		_, err := u.LogCmd(exec.Command(cmd, args...),
+			"deleting group %q", g.Name)
+		if err != nil {
+			return fmt.Errorf("failed to delete group %q: %v",
+				g.Name, err)
 		}
 		return nil
+	case exists:
+		_, err = u.LogCmd(exec.Command(cmd, args...),
+			"modifying group %q", g.Name)
+	default:
+		_, err = u.LogCmd(exec.Command(cmd, args...),
+			"adding group %q", g.Name)
+	}
+
+	return err
+}
+
+// groupCmd returns the command and arguments EnsureGroup runs for the group,
+// given whether it already exists. The command is empty if nothing needs to
+// be done.
+func (u Util) groupCmd(g types.PasswdGroup, exists bool) (string, []string) {
+	if util.IsFalse(g.ShouldExist) {
+		if exists {
+			return distro.GroupdelCmd(), []string{"--root", u.DestDir, g.Name}
+		}
+		return "", nil
 	}
 
 	args := []string{"--root", u.DestDir}
@@ -341,21 +424,37 @@ func (u Util) EnsureGroup(g types.PasswdGroup) error {
 		args = append(args, "--password", *g.PasswordHash)
 	}
 
-	var cmd string
 	if exists {
-		cmd = distro.GroupmodCmd()
-		args = append(args, g.Name)
-		_, err = u.LogCmd(exec.Command(cmd, args...),
-			"modifying group %q", g.Name)
-	} else {
-		cmd = distro.GroupaddCmd()
-		args = appendIfTrue(args, g.System, "--system")
-		args = append(args, g.Name)
-		_, err = u.LogCmd(exec.Command(cmd, args...),
-			"adding group %q", g.Name)
+		return distro.GroupmodCmd(), append(args, g.Name)
 	}
+	args = appendIfTrue(args, g.System, "--system")
+	return distro.GroupaddCmd(), append(args, g.Name)
+}
 
-	return err
+// PlanGroup returns the operations EnsureGroup would perform for the group.
+// Password hashes are not included in the plan.
+func (u Util) PlanGroup(g types.PasswdGroup) ([]plan.Operation, error) {
+	exists, err := u.CheckIfGroupExists(g)
+	if err != nil {
+		return nil, err
+	}
+	cmd, args := u.groupCmd(g, exists)
+	if cmd == "" {
+		return nil, nil
+	}
+	description := fmt.Sprintf("add group %q", g.Name)
+	switch {
+	case util.IsFalse(g.ShouldExist):
+		description = fmt.Sprintf("delete group %q", g.Name)
+	case exists:
+		description = fmt.Sprintf("modify group %q", g.Name)
+	}
+	return []plan.Operation{{
+		Kind:        plan.KindGroup,
+		Target:      g.Name,
+		Description: description,
+		Command:     append([]string{cmd}, redactPasswordArgs(args)...),
+	}}, nil
 }
 
 // CheckIfGroupExists will return Info log when group is empty
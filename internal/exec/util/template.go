@@ -0,0 +1,73 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// templateRe matches escaped placeholders ($${name}) and placeholders
+// (${name}).
+var templateRe = regexp.MustCompile(`\$?\$\{([A-Za-z0-9_]+)\}`)
+
+// RenderTemplate substitutes each ${name} in data with the value of the
+// variable. $${name} is replaced with a literal ${name}. Other uses of $ are
+// left alone. It is an error to reference an undefined variable.
+func RenderTemplate(data []byte, variables map[string]string) ([]byte, error) {
+	var err error
+	out := templateRe.ReplaceAllFunc(data, func(match []byte) []byte {
+		if bytes.HasPrefix(match, []byte("$$")) {
+			return match[1:]
+		}
+		name := string(match[2 : len(match)-1])
+		value, ok := variables[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("undefined template variable %q", name)
+			}
+			return match
+		}
+		return []byte(value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// renderTemplateFile renders the contents of f as a template, replacing
+// them with the result.
+func renderTemplateFile(f *os.File, variables map[string]string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	rendered, err := RenderTemplate(data, variables)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = f.WriteAt(rendered, 0)
+	return err
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	variables := map[string]string{
+		"hostname":    "node1",
+		"instance_id": "i-0123456789abcdef0",
+		"empty":       "",
+	}
+
+	tests := []struct {
+		in  string
+		out string
+		err bool
+	}{
+		{
+			in:  "no placeholders",
+			out: "no placeholders",
+		},
+		{
+			in:  "name=${hostname} id=${instance_id}${empty}\n",
+			out: "name=node1 id=i-0123456789abcdef0\n",
+		},
+		{
+			in:  "PATH=$PATH:/opt/bin $1 ${not valid} $",
+			out: "PATH=$PATH:/opt/bin $1 ${not valid} $",
+		},
+		{
+			in:  "literal $${hostname}, value ${hostname}",
+			out: "literal ${hostname}, value node1",
+		},
+		{
+			in:  "${hostname} ${missing}",
+			err: true,
+		},
+	}
+
+	for i, test := range tests {
+		out, err := RenderTemplate([]byte(test.in), variables)
+		if test.err {
+			assert.Error(t, err, "#%d: expected error", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d: unexpected error", i)
+		assert.Equal(t, test.out, string(out), "#%d: bad output", i)
+	}
+}
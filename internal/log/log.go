@@ -19,7 +19,11 @@ import (
 	"fmt"
 	"log/syslog"
 	"os/exec"
+	"slices"
 	"strings"
+	"sync"
+
+	"github.com/coreos/ignition/v2/internal/redact"
 
 	"github.com/coreos/vcontext/report"
 )
@@ -41,13 +45,22 @@ type Logger struct {
 	ops           LoggerOps
 	prefixStack   []string
 	opSequenceNum int
+	recorder      *Recorder
+	// secrets is shared by copies of the Logger, so that secrets added
+	// via any copy are redacted by all of them.
+	secrets *secretList
+}
+
+type secretList struct {
+	mu     sync.RWMutex
+	values []string
 }
 
 // New creates a new logger.
 // If logToStdout is true, syslog is tried first. If syslog fails or logToStdout
 // is false Stdout is used.
 func New(logToStdout bool) Logger {
-	logger := Logger{}
+	logger := Logger{secrets: &secretList{}}
 	if !logToStdout {
 		var err error
 		logger.ops, err = syslog.New(syslog.LOG_DEBUG, "ignition")
@@ -61,6 +74,50 @@ func New(logToStdout bool) Logger {
 	return logger
 }
 
+// NewStderr creates a new logger which logs to stderr.
+func NewStderr() Logger {
+	return Logger{ops: Stderr{}, secrets: &secretList{}}
+}
+
+// AddSecrets registers values which should be redacted from all messages
+// logged from now on, including by copies of the Logger. Values shorter
+// than redact.MinSecretLength, such as the "*" of a locked account's
+// password hash, are ignored. Contents of data URLs and passwords in URLs
+// are always redacted.
+func (l Logger) AddSecrets(values ...string) {
+	if l.secrets == nil {
+		return
+	}
+	l.secrets.mu.Lock()
+	defer l.secrets.mu.Unlock()
+	for _, v := range values {
+		if len(v) >= redact.MinSecretLength {
+			l.secrets.values = append(l.secrets.values, v)
+		}
+	}
+}
+
+// Redact returns s with registered secrets, contents of data URLs, and
+// passwords in URLs replaced with a placeholder.
+func (l Logger) Redact(s string) string {
+	if l.secrets != nil {
+		l.secrets.mu.RLock()
+		for _, v := range l.secrets.values {
+			s = strings.ReplaceAll(s, v, redact.Placeholder)
+		}
+		l.secrets.mu.RUnlock()
+	}
+	return redact.Text(s)
+}
+
+// Clone returns a copy of the logger with its own prefix stack, which can be
+// used concurrently with l. The copy shares l's output, recorder, and
+// secrets.
+func (l Logger) Clone() Logger {
+	l.prefixStack = slices.Clone(l.prefixStack)
+	return l
+}
+
 // Close closes the logger. Ignore errors.
 func (l Logger) Close() {
 	_ = l.ops.Close()
@@ -136,12 +193,29 @@ func QuotedCmd(cmd *exec.Cmd) string {
 	return strings.Join(q, ` `)
 }
 
+// RedactedCmd returns the same as QuotedCmd, with secrets redacted from the
+// arguments.
+func (l Logger) RedactedCmd(cmd *exec.Cmd) string {
+	return QuotedCmd(&exec.Cmd{
+		Path: cmd.Path,
+		Args: l.redactArgs(cmd.Args),
+	})
+}
+
+func (l Logger) redactArgs(args []string) []string {
+	var ret []string
+	for _, arg := range args {
+		ret = append(ret, l.Redact(arg))
+	}
+	return ret
+}
+
 // LogCmd runs and logs the supplied cmd as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
 // The exact command path and arguments being executed are also logged for debugging assistance.
 func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
 	code := -1
 	f := func() error {
-		cmdLine := QuotedCmd(cmd)
+		cmdLine := l.RedactedCmd(cmd)
 		l.Debug("executing: %s", cmdLine)
 
 		stdout := &bytes.Buffer{}
@@ -152,27 +226,47 @@ func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, er
 			if exitErr, ok := err.(*exec.ExitError); ok {
 				code = exitErr.ExitCode()
 			}
-			return fmt.Errorf("%v: Cmd: %s Stdout: %q Stderr: %q", err, cmdLine, stdout.Bytes(), stderr.Bytes())
+			return fmt.Errorf("%v: Cmd: %s Stdout: %q Stderr: %q", err, cmdLine, l.Redact(stdout.String()), l.Redact(stderr.String()))
 		}
 		return nil
 	}
-	err := l.LogOp(f, format, a...)
+	record, err := l.logOp(f, format, a...)
+	record.Command = l.redactArgs(cmd.Args)
+	if err == nil {
+		record.ExitStatus = new(int)
+	} else if code != -1 {
+		record.ExitStatus = &code
+	}
+	l.recordOperation(record)
 	return code, err
 }
 
 // LogOp calls and logs the supplied function as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
 func (l *Logger) LogOp(op func() error, format string, a ...interface{}) error {
+	record, err := l.logOp(op, format, a...)
+	l.recordOperation(record)
+	return err
+}
+
+// logOp implements LogOp, returning a record of the operation for the
+// caller to complete and record.
+func (l *Logger) logOp(op func() error, format string, a ...interface{}) (Operation, error) {
 	l.opSequenceNum++
 	l.PushPrefix("op(%x)", l.opSequenceNum)
 	defer l.PopPrefix()
 
+	record := Operation{
+		Description: l.Redact(fmt.Sprintf(format, a...)),
+	}
 	l.logStart(format, a...)
 	if err := op(); err != nil {
-		l.logFail("%s: %v", fmt.Sprintf(format, a...), err)
-		return err
+		l.logFail("%s: %v", record.Description, err)
+		record.Error = l.Redact(err.Error())
+		return record, err
 	}
 	l.logFinish(format, a...)
-	return nil
+	record.Success = true
+	return record, nil
 }
 
 // LogReport logs entries from the report at appropriate levels.
@@ -216,5 +310,5 @@ func (l Logger) sprintf(format string, a ...interface{}) string {
 		m = append(m, fmt.Sprintf("%s:", pfx))
 	}
 	m = append(m, fmt.Sprintf(format, a...))
-	return strings.Join(m, " ")
+	return l.Redact(strings.Join(m, " "))
 }
@@ -0,0 +1,32 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"testing"
+)
+
+func TestAddSecrets(t *testing.T) {
+	logger := NewStderr()
+	// short values, such as the password hash of a locked account,
+	// aren't redacted
+	logger.AddSecrets("*", "!", "", "$6$rounds=4096$salt$hash")
+	clone := logger.Clone()
+	in := "set password of core to $6$rounds=4096$salt$hash; locked root with * and !"
+	expected := "set password of core to REDACTED; locked root with * and !"
+	if out := clone.Redact(in); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+}
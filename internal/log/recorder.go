@@ -0,0 +1,82 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"sync"
+)
+
+// Recorder collects a structured record of the operations logged via LogOp
+// and LogCmd, and of the resources fetched, so that they can be reported
+// without parsing log messages.
+type Recorder struct {
+	Operations []Operation `json:"operations,omitempty"`
+	Fetches    []Fetch     `json:"fetches,omitempty"`
+
+	// resources may be fetched concurrently
+	mu sync.Mutex
+}
+
+// Operation is the record of a single LogOp or LogCmd call.
+type Operation struct {
+	Description string `json:"description"`
+	// Command is only set for operations logged with LogCmd.
+	Command []string `json:"command,omitempty"`
+	// ExitStatus is only set for operations logged with LogCmd, and
+	// only if the command was run.
+	ExitStatus *int   `json:"exitStatus,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Fetch is the record of a successfully fetched resource.
+type Fetch struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+}
+
+// SetRecorder starts recording operations and fetches into r. A nil r stops
+// recording.
+func (l *Logger) SetRecorder(r *Recorder) {
+	l.recorder = r
+}
+
+// Recorder returns the current recorder, or nil if not recording.
+func (l Logger) Recorder() *Recorder {
+	return l.recorder
+}
+
+// RecordFetch records that the resource at url was fetched and that its
+// contents have the given hash. url should already be redacted.
+func (l Logger) RecordFetch(url, hash string) {
+	if l.recorder == nil {
+		return
+	}
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+	l.recorder.Fetches = append(l.recorder.Fetches, Fetch{
+		URL:  url,
+		Hash: hash,
+	})
+}
+
+func (l Logger) recordOperation(op Operation) {
+	if l.recorder == nil {
+		return
+	}
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+	l.recorder.Operations = append(l.recorder.Operations, op)
+}
@@ -16,6 +16,7 @@ package log
 
 import (
 	"fmt"
+	"os"
 )
 
 type Stdout struct{}
@@ -29,3 +30,21 @@ func (Stdout) Notice(msg string) error  { fmt.Println("NOTICE   :", msg); return
 func (Stdout) Info(msg string) error    { fmt.Println("INFO     :", msg); return nil }
 func (Stdout) Debug(msg string) error   { fmt.Println("DEBUG    :", msg); return nil }
 func (Stdout) Close() error             { return nil }
+
+// Stderr logs to stderr, for use when stdout carries other output.
+type Stderr struct{}
+
+func (Stderr) Emerg(msg string) error   { return logStderr("EMERGENCY:", msg) }
+func (Stderr) Alert(msg string) error   { return logStderr("ALERT    :", msg) }
+func (Stderr) Crit(msg string) error    { return logStderr("CRITICAL :", msg) }
+func (Stderr) Err(msg string) error     { return logStderr("ERROR    :", msg) }
+func (Stderr) Warning(msg string) error { return logStderr("WARNING  :", msg) }
+func (Stderr) Notice(msg string) error  { return logStderr("NOTICE   :", msg) }
+func (Stderr) Info(msg string) error    { return logStderr("INFO     :", msg) }
+func (Stderr) Debug(msg string) error   { return logStderr("DEBUG    :", msg) }
+func (Stderr) Close() error             { return nil }
+
+func logStderr(prefix, msg string) error {
+	_, _ = fmt.Fprintln(os.Stderr, prefix, msg)
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/coreos/ignition/v2/internal/exec"
 	"github.com/coreos/ignition/v2/internal/exec/stages"
 	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/plan"
 	"github.com/coreos/ignition/v2/internal/platform"
 	_ "github.com/coreos/ignition/v2/internal/register"
 	"github.com/coreos/ignition/v2/internal/state"
@@ -48,9 +49,12 @@ func main() {
 
 func ignitionMain() {
 	flags := struct {
+		cacheDir     string
 		configCache  string
+		dryRun       bool
 		fetchTimeout time.Duration
 		needNet      string
+		planFile     string
 		platform     platform.Name
 		root         string
 		stage        stages.Name
@@ -60,8 +64,11 @@ func ignitionMain() {
 	}{}
 
 	flag.StringVar(&flags.configCache, "config-cache", "/run/ignition.json", "where to cache the config")
+	flag.BoolVar(&flags.dryRun, "dry-run", false, "write the operations the stage would perform to --plan-file instead of performing them; stages other than the fetch stages need a --config-cache written by a fetch stage run without --dry-run")
+	flag.StringVar(&flags.cacheDir, "fetch-cache-dir", "", "directory in which to cache fetched resources with a verification hash")
 	flag.DurationVar(&flags.fetchTimeout, "fetch-timeout", exec.DefaultFetchTimeout, "initial duration for which to wait for config")
 	flag.StringVar(&flags.needNet, "neednet", "/run/ignition/neednet", "flag file to write from fetch-offline if networking is needed")
+	flag.StringVar(&flags.planFile, "plan-file", "-", "where to write the plan in dry-run mode, or - for stdout")
 	flag.Var(&flags.platform, "platform", fmt.Sprintf("current platform. %v", platform.Names()))
 	flag.StringVar(&flags.root, "root", "/", "root of the filesystem")
 	flag.Var(&flags.stage, "stage", fmt.Sprintf("execution stage. %v", stages.Names()))
@@ -87,6 +94,10 @@ func ignitionMain() {
 	}
 
 	logger := log.New(flags.logToStdout)
+	if flags.logToStdout && flags.dryRun && flags.planFile == "-" {
+		// keep stdout for the plan
+		logger = log.NewStderr()
+	}
 	defer logger.Close()
 
 	logger.Info("%s", version.String)
@@ -109,12 +120,29 @@ func ignitionMain() {
 		Logger:         &logger,
 		NeedNet:        flags.needNet,
 		ConfigCache:    flags.configCache,
+		FetchCacheDir:  flags.cacheDir,
 		PlatformConfig: platformConfig,
 		Fetcher:        &fetcher,
 		State:          &state,
 	}
+	if flags.dryRun {
+		engine.Plan = &plan.Plan{}
+		logger.Info("Dry run: no changes will be made")
+	}
 
 	err = engine.Run(flags.stage.String())
+	if flags.dryRun {
+		if err != nil {
+			logger.Crit("Ignition failed: %v", err.Error())
+			os.Exit(1)
+		}
+		if err := writePlan(*engine.Plan, flags.planFile); err != nil {
+			logger.Crit("writing plan: %v", err)
+			os.Exit(1)
+		}
+		logger.Info("Ignition dry run finished successfully")
+		return
+	}
 	if statusErr := engine.PlatformConfig.Status(flags.stage.String(), *engine.Fetcher, err); statusErr != nil {
 		logger.Err("POST Status error: %v", statusErr.Error())
 	}
@@ -131,11 +159,16 @@ func ignitionMain() {
 
 func ignitionApplyMain() {
 	printVersion := false
+	dryRun := false
+	planFile := ""
 	flags := apply.Flags{}
 	pflag.BoolVar(&printVersion, "version", false, "print the version of ignition-apply")
 	pflag.StringVar(&flags.Root, "root", "/", "root of the filesystem")
 	pflag.BoolVar(&flags.IgnoreUnsupported, "ignore-unsupported", false, "ignore unsupported config sections")
 	pflag.BoolVar(&flags.Offline, "offline", false, "error out if config references remote resources")
+	pflag.StringVar(&flags.CacheDir, "cache-dir", "", "directory in which to cache fetched resources with a verification hash")
+	pflag.BoolVar(&dryRun, "dry-run", false, "write the operations that would be performed to --plan-file instead of performing them")
+	pflag.StringVar(&planFile, "plan-file", "-", "where to write the plan in dry-run mode, or - for stdout")
 	pflag.Usage = func() {
 		_, _ = fmt.Fprintf(pflag.CommandLine.Output(), "Usage: %s [options] config.ign\n", os.Args[0])
 		_, _ = fmt.Fprintf(pflag.CommandLine.Output(), "Options:\n")
@@ -155,6 +188,10 @@ func ignitionApplyMain() {
 	cfgArg := pflag.Arg(0)
 
 	logger := log.New(true)
+	if dryRun && planFile == "-" {
+		// keep stdout for the plan
+		logger = log.NewStderr()
+	}
 	defer logger.Close()
 
 	logger.Info("%s", version.String)
@@ -179,12 +216,41 @@ func ignitionApplyMain() {
 		os.Exit(1)
 	}
 
+	if dryRun {
+		p, err := apply.Plan(cfg, flags, &logger)
+		if err != nil {
+			logger.Crit("failed to plan: %v", err)
+			os.Exit(1)
+		}
+		if err := writePlan(p, planFile); err != nil {
+			logger.Crit("writing plan: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := apply.Run(cfg, flags, &logger); err != nil {
 		logger.Crit("failed to apply: %v", err)
 		os.Exit(1)
 	}
 }
 
+// writePlan writes the plan to path, or to stdout if path is "-".
+func writePlan(p plan.Plan, path string) error {
+	if path == "-" {
+		return p.Write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func ignitionRmCfgMain() {
 	flags := struct {
 		logToStdout bool
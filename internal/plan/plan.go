@@ -0,0 +1,85 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The plan package describes the changes Ignition would make to a system
+// when run in dry-run mode.
+
+package plan
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Kinds of operations which can appear in a plan.
+const (
+	KindPartitionTable = "partition-table"
+	KindPartition      = "partition"
+	KindRaid           = "raid"
+	KindLuks           = "luks"
+	KindWipe           = "wipe"
+	KindFilesystem     = "filesystem"
+	KindMount          = "mount"
+	KindUmount         = "umount"
+	KindGroup          = "group"
+	KindUser           = "user"
+	KindFile           = "file"
+	KindDirectory      = "directory"
+	KindLink           = "link"
+	KindArchive        = "archive"
+	KindUnit           = "unit"
+	KindKargs          = "kargs"
+	KindConfigCache    = "config-cache"
+)
+
+// Operation is a single change that a stage would make.
+type Operation struct {
+	Stage       string   `json:"stage"`
+	Kind        string   `json:"kind"`
+	Target      string   `json:"target"`
+	Description string   `json:"description"`
+	Command     []string `json:"command,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Hash        string   `json:"hash,omitempty"`
+}
+
+// Plan is the ordered list of operations that one or more stages would
+// perform.
+type Plan struct {
+	Operations []Operation `json:"operations"`
+}
+
+// Add appends the operations to the plan, recording them as belonging to
+// the given stage.
+func (p *Plan) Add(stage string, ops ...Operation) {
+	for _, op := range ops {
+		op.Stage = stage
+		p.Operations = append(p.Operations, op)
+	}
+}
+
+// Write writes the plan to w as indented JSON.
+func (p Plan) Write(w io.Writer) error {
+	if p.Operations == nil {
+		// always emit a list, even if it's empty
+		p.Operations = []Operation{}
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
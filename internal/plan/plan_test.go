@@ -0,0 +1,82 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	var p Plan
+	p.Add("disks", Operation{Kind: KindFilesystem, Target: "/dev/sda1"}, Operation{Kind: KindWipe, Target: "/dev/sda2"})
+	p.Add("files", Operation{Stage: "ignored", Kind: KindFile, Target: "/etc/hostname"})
+
+	assert.Equal(t, []Operation{
+		{Stage: "disks", Kind: KindFilesystem, Target: "/dev/sda1"},
+		{Stage: "disks", Kind: KindWipe, Target: "/dev/sda2"},
+		{Stage: "files", Kind: KindFile, Target: "/etc/hostname"},
+	}, p.Operations)
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		in  Plan
+		out string
+	}{
+		{
+			in:  Plan{},
+			out: "{\n  \"operations\": []\n}\n",
+		},
+		{
+			in: Plan{
+				Operations: []Operation{
+					{
+						Stage:       "kargs",
+						Kind:        KindKargs,
+						Target:      "kernel command line",
+						Description: "update kernel arguments",
+						Command:     []string{"ignition-kargs-helper", "--should-exist", "foo"},
+					},
+				},
+			},
+			out: `{
+  "operations": [
+    {
+      "stage": "kargs",
+      "kind": "kargs",
+      "target": "kernel command line",
+      "description": "update kernel arguments",
+      "command": [
+        "ignition-kargs-helper",
+        "--should-exist",
+        "foo"
+      ]
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+		err := test.in.Write(&buf)
+		assert.NoError(t, err, "#%d: unexpected error", i)
+		assert.Equal(t, test.out, buf.String(), "#%d: bad output", i)
+	}
+}
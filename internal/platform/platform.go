@@ -48,6 +48,9 @@ type Provider struct {
 	Init       func(f *resource.Fetcher) error
 	Status     func(stageName string, f resource.Fetcher, e error) error
 	DelConfig  func(f *resource.Fetcher) error
+	// Metadata returns details of the instance from the platform's
+	// metadata service, for use in file templates.
+	Metadata func(f *resource.Fetcher) (map[string]string, error)
 
 	// Fetch, and also save output files to be written during files stage.
 	// Avoid, unless you're certain you need it.
@@ -104,6 +107,15 @@ func (c Config) DelConfig(f *resource.Fetcher) error {
 	}
 }
 
+// Metadata returns the instance metadata exposed to file templates, or nil
+// if the platform doesn't provide any.
+func (c Config) Metadata(f *resource.Fetcher) (map[string]string, error) {
+	if c.p.Metadata != nil {
+		return c.p.Metadata(f)
+	}
+	return nil, nil
+}
+
 var configs = registry.Create("platform configs")
 
 func Register(provider Provider) {
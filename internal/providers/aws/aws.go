@@ -42,6 +42,18 @@ var (
 		Host:   "169.254.169.254",
 		Path:   "2019-10-01/user-data",
 	}
+	metadataURL = url.URL{
+		Scheme: "http",
+		Host:   "169.254.169.254",
+		Path:   "2021-01-03/meta-data/",
+	}
+	// template variables and the metadata paths they're read from
+	metadataPaths = map[string]string{
+		"instance_id":       "instance-id",
+		"instance_type":     "instance-type",
+		"region":            "placement/region",
+		"availability_zone": "placement/availability-zone",
+	}
 	imdsTokenURL = url.URL{
 		Scheme: "http",
 		Host:   "169.254.169.254",
@@ -56,6 +68,7 @@ func init() {
 		NewFetcher: newFetcher,
 		Fetch:      fetchConfig,
 		Init:       doInit,
+		Metadata:   fetchMetadata,
 	})
 }
 
@@ -68,6 +81,22 @@ func fetchConfig(f *resource.Fetcher) (types.Config, report.Report, error) {
 	return util.ParseConfig(f.Logger, data)
 }
 
+func fetchMetadata(f *resource.Fetcher) (map[string]string, error) {
+	metadata := map[string]string{}
+	for name, path := range metadataPaths {
+		u := metadataURL
+		u.Path += path
+		data, err := fetchFromAWSMetadata(u, resource.FetchOptions{}, f)
+		if err == resource.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		metadata[name] = string(data)
+	}
+	return metadata, nil
+}
+
 func newFetcher(l *log.Logger) (resource.Fetcher, error) {
 	cfg := aws.Config{Credentials: aws.NewCredentialsCache(ec2rolecreds.New())}
 	return resource.Fetcher{
@@ -20,6 +20,7 @@ package gcp
 import (
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/platform"
@@ -35,14 +36,26 @@ var (
 		Host:   "169.254.169.254",
 		Path:   "computeMetadata/v1/instance/attributes/user-data",
 	}
+	metadataURL = url.URL{
+		Scheme: "http",
+		Host:   "169.254.169.254",
+		Path:   "computeMetadata/v1/",
+	}
+	// template variables and the metadata paths they're read from
+	metadataPaths = map[string]string{
+		"instance_id": "instance/id",
+		"project_id":  "project/project-id",
+		"zone":        "instance/zone",
+	}
 	metadataHeaderKey = "Metadata-Flavor"
 	metadataHeaderVal = "Google"
 )
 
 func init() {
 	platform.Register(platform.Provider{
-		Name:  "gcp",
-		Fetch: fetchConfig,
+		Name:     "gcp",
+		Fetch:    fetchConfig,
+		Metadata: fetchMetadata,
 	})
 }
 
@@ -58,3 +71,28 @@ func fetchConfig(f *resource.Fetcher) (types.Config, report.Report, error) {
 
 	return util.ParseConfig(f.Logger, data)
 }
+
+func fetchMetadata(f *resource.Fetcher) (map[string]string, error) {
+	headers := make(http.Header)
+	headers.Set(metadataHeaderKey, metadataHeaderVal)
+	metadata := map[string]string{}
+	for name, path := range metadataPaths {
+		u := metadataURL
+		u.Path += path
+		data, err := f.FetchToBuffer(u, resource.FetchOptions{
+			Headers: headers,
+		})
+		if err == resource.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		value := string(data)
+		if name == "zone" {
+			// projects/<number>/zones/<zone>
+			value = value[strings.LastIndex(value, "/")+1:]
+		}
+		metadata[name] = value
+	}
+	return metadata, nil
+}
@@ -21,6 +21,7 @@ package openstack
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/url"
@@ -60,17 +61,25 @@ var (
 			Path:   "openstack/latest/user_data",
 		},
 	}
+
+	metadataURL = url.URL{
+		Scheme: "http",
+		Host:   "169.254.169.254",
+		Path:   "openstack/latest/meta_data.json",
+	}
 )
 
 func init() {
 	platform.Register(platform.Provider{
-		Name:  "openstack",
-		Fetch: fetchConfig,
+		Name:     "openstack",
+		Fetch:    fetchConfig,
+		Metadata: fetchMetadata,
 	})
 	// the brightbox platform ID just uses the OpenStack provider code
 	platform.Register(platform.Provider{
-		Name:  "brightbox",
-		Fetch: fetchConfig,
+		Name:     "brightbox",
+		Fetch:    fetchConfig,
+		Metadata: fetchMetadata,
 	})
 }
 
@@ -135,6 +144,37 @@ Loop:
 	return util.ParseConfig(f.Logger, data)
 }
 
+// fetchMetadata reads the instance details from the IPv4 metadata service.
+func fetchMetadata(f *resource.Fetcher) (map[string]string, error) {
+	data, err := f.FetchToBuffer(metadataURL, resource.FetchOptions{})
+	if err == resource.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		UUID             string `json:"uuid"`
+		Name             string `json:"name"`
+		AvailabilityZone string `json:"availability_zone"`
+		ProjectID        string `json:"project_id"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parsing metadata: %v", err)
+	}
+	metadata := map[string]string{}
+	for name, value := range map[string]string{
+		"instance_id":       meta.UUID,
+		"instance_name":     meta.Name,
+		"availability_zone": meta.AvailabilityZone,
+		"project_id":        meta.ProjectID,
+	} {
+		if value != "" {
+			metadata[name] = value
+		}
+	}
+	return metadata, nil
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return (err == nil)
@@ -0,0 +1,114 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The redact package removes secrets from configs and log messages. Fields
+// which may contain secrets are marked by the types.Sensitive interface.
+// Secrets which can appear anywhere, such as the contents of data URLs and
+// passwords in URLs, are recognized by their form.
+package redact
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+)
+
+const (
+	// Placeholder replaces redacted values.
+	Placeholder = "REDACTED"
+
+	// MinSecretLength is the length below which sensitive values aren't
+	// redacted, since replacing them throughout the logs would mangle
+	// unrelated messages.
+	MinSecretLength = 8
+)
+
+var (
+	// data URLs must start a token, so that words ending in "data" aren't
+	// mistaken for them
+	dataURLRe     = regexp.MustCompile(`(^|[\s"'=])data:[^\s"'<>\\,]*,[^\s"'<>\\]*`)
+	urlPasswordRe = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://[^/\s:@"']*:)[^/\s@"']*@`)
+)
+
+// Text redacts the contents of data URLs and the passwords of URLs in s.
+func Text(s string) string {
+	s = dataURLRe.ReplaceAllString(s, "${1}data:,"+Placeholder)
+	return urlPasswordRe.ReplaceAllString(s, "${1}"+Placeholder+"@")
+}
+
+// Config returns a copy of cfg with sensitive fields, data URLs, and URL
+// passwords redacted.
+func Config(cfg types.Config) (types.Config, error) {
+	// deep copy so that we don't modify the caller's config
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return types.Config{}, err
+	}
+	var ret types.Config
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return types.Config{}, err
+	}
+	walk(reflect.ValueOf(&ret).Elem(), func(v reflect.Value, sensitive bool) {
+		if sensitive {
+			v.SetString(Placeholder)
+		} else {
+			v.SetString(Text(v.String()))
+		}
+	})
+	return ret, nil
+}
+
+// Secrets returns the values of the sensitive string fields in cfg, so that
+// they can be redacted wherever they appear. Short values are omitted.
+func Secrets(cfg types.Config) []string {
+	var secrets []string
+	walk(reflect.ValueOf(&cfg).Elem(), func(v reflect.Value, sensitive bool) {
+		if sensitive && len(v.String()) >= MinSecretLength && !slices.Contains(secrets, v.String()) {
+			secrets = append(secrets, v.String())
+		}
+	})
+	return secrets
+}
+
+// walk calls fn for each string in v, reporting whether it is within a
+// sensitive field.
+func walk(v reflect.Value, fn func(v reflect.Value, sensitive bool)) {
+	walkSensitive(v, false, fn)
+}
+
+func walkSensitive(v reflect.Value, sensitive bool, fn func(v reflect.Value, sensitive bool)) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walkSensitive(v.Elem(), sensitive, fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkSensitive(v.Index(i), sensitive, fn)
+		}
+	case reflect.Struct:
+		var fields []string
+		if s, ok := v.Interface().(types.Sensitive); ok {
+			fields = s.SensitiveFields()
+		}
+		for i := 0; i < v.NumField(); i++ {
+			walkSensitive(v.Field(i), sensitive || slices.Contains(fields, v.Type().Field(i).Name), fn)
+		}
+	case reflect.String:
+		fn(v, sensitive)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+var (
+	// emptyPayloadHash is the SHA-256 of an empty request body, as
+	// required by SigV4 for GET requests.
+	emptyPayloadHash = hex.EncodeToString(sha256.New().Sum(nil))
+)
+
+// authorizer returns a function which authenticates each HTTP request for
+// u according to auth. Tokens are obtained once, up front; SigV4
+// signatures are computed per request since they include a timestamp.
+func (f *Fetcher) authorizer(u url.URL, auth types.Auth) (func(*http.Request) error, error) {
+	if auth.Type == nil {
+		return nil, nil
+	}
+	ctx := context.Background()
+	var token string
+	switch *auth.Type {
+	case "bearer":
+		tokenURL, err := url.Parse(*auth.TokenSource)
+		if err != nil {
+			return nil, err
+		}
+		data, err := f.FetchToBuffer(*tokenURL, FetchOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("fetching bearer token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	case "aws-sigv4":
+		return f.awsSigner(ctx, auth)
+	case "gcp-identity":
+		audience := u.Scheme + "://" + u.Host
+		if auth.Audience != nil && *auth.Audience != "" {
+			audience = *auth.Audience
+		}
+		if !metadata.OnGCE() {
+			return nil, errors.New("gcp-identity auth requires the GCE metadata service")
+		}
+		var err error
+		token, err = metadata.GetWithContext(ctx, "instance/service-accounts/default/identity?audience="+url.QueryEscape(audience)+"&format=full")
+		if err != nil {
+			return nil, fmt.Errorf("fetching GCP identity token: %w", err)
+		}
+	case "azure-managed-identity":
+		var err error
+		token, err = f.azureManagedIdentityToken(ctx, *auth.Audience)
+		if err != nil {
+			return nil, fmt.Errorf("fetching Azure managed identity token: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", *auth.Type)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("empty token for %s auth", *auth.Type)
+	}
+	f.Logger.AddSecrets(token)
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}, nil
+}
+
+// awsSigner returns a function which signs requests with SigV4, using the
+// same credentials as S3 fetches.
+func (f *Fetcher) awsSigner(ctx context.Context, auth types.Auth) (func(*http.Request) error, error) {
+	if f.AWSConfig == nil || f.AWSConfig.Credentials == nil {
+		return nil, errors.New("aws-sigv4 auth requires AWS credentials")
+	}
+	region := f.AWSConfig.Region
+	if auth.AwsRegion != nil && *auth.AwsRegion != "" {
+		region = *auth.AwsRegion
+	} else if region == "" {
+		region = f.S3RegionHint
+	}
+	if region == "" {
+		return nil, errors.New("aws-sigv4 auth requires awsRegion when not running on AWS")
+	}
+	creds, err := f.AWSConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+	f.Logger.AddSecrets(creds.SecretAccessKey, creds.SessionToken)
+	signer := v4.NewSigner()
+	return func(req *http.Request) error {
+		return signer.SignHTTP(ctx, creds, req, emptyPayloadHash, *auth.AwsService, region, time.Now())
+	}, nil
+}
+
+// azureManagedIdentityToken returns an access token for the given audience,
+// reusing the Azure Blob Storage credential if one is configured.
+func (f *Fetcher) azureManagedIdentityToken(ctx context.Context, audience string) (string, error) {
+	var cred azcore.TokenCredential
+	if f.AzSession != nil {
+		cred = f.AzSession
+	} else {
+		mi, err := azidentity.NewManagedIdentityCredential(nil)
+		if err != nil {
+			return "", err
+		}
+		cred = mi
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{strings.TrimSuffix(audience, "/") + "/.default"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+		AWSConfig: &aws.Config{
+			Region: "us-west-2",
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+			}),
+		},
+	}
+
+	// bearer token read from another resource, with trailing newline
+	_, err = f.FetchToBuffer(*u, FetchOptions{
+		Auth: types.Auth{
+			Type:        util.StrToPtr("bearer"),
+			TokenSource: util.StrToPtr("data:,s3cr3t-token%0A"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer s3cr3t-token", authorization)
+
+	// SigV4 with the fetcher's AWS credentials
+	_, err = f.FetchToBuffer(*u, FetchOptions{
+		Auth: types.Auth{
+			Type:       util.StrToPtr("aws-sigv4"),
+			AwsService: util.StrToPtr("execute-api"),
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), authorization)
+	assert.Contains(t, authorization, "/us-west-2/execute-api/aws4_request")
+
+	// SigV4 without credentials
+	f.AWSConfig = nil
+	_, err = f.FetchToBuffer(*u, FetchOptions{
+		Auth: types.Auth{
+			Type:       util.StrToPtr("aws-sigv4"),
+			AwsService: util.StrToPtr("execute-api"),
+		},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/ignition/v2/internal/util"
+)
+
+// cacheKey returns the name of the cache entry for the resource, or "" if
+// it can't be cached.
+func (f *Fetcher) cacheKey(u url.URL, opts FetchOptions) string {
+	if f.CacheDir == "" {
+		return ""
+	}
+	return contentKey(u, opts)
+}
+
+// contentKey returns the key under which the resource is cached and shared
+// with peers, or "" if it can't be. Only remote resources with a
+// verification hash have one, since the hash lets us check a cached or
+// shared copy before using it. The key includes the hash function, since
+// sums of different functions can have the same length.
+func contentKey(u url.URL, opts FetchOptions) string {
+	if opts.Hash == nil || len(opts.ExpectedSum) == 0 || !UrlNeedsNet(u) {
+		return ""
+	}
+	function := util.HashName(opts.Hash)
+	if function == "" {
+		return ""
+	}
+	return function + "-" + hex.EncodeToString(opts.ExpectedSum)
+}
+
+// fetchWithCache satisfies the fetch from the cache if possible, falling
+// back to fetching the resource and adding it to the cache.
+func (f *Fetcher) fetchWithCache(u url.URL, dest *os.File, opts FetchOptions) error {
+	key := f.cacheKey(u, opts)
+	if key == "" {
+		return f.fetchWithPeers(u, dest, opts)
+	}
+	if f.readCacheEntry(key, dest, opts) {
+		f.Logger.Info("using cached copy of %s", RedactedURL(u))
+		f.shareCacheEntry(key, u, opts)
+		return nil
+	}
+	if err := f.fetchWithPeers(u, dest, opts); err != nil {
+		return err
+	}
+	f.writeCacheEntry(key, dest)
+	f.shareCacheEntry(key, u, opts)
+	return nil
+}
+
+// fetchToBufferWithCache is the FetchToBuffer counterpart of
+// fetchWithCache.
+func (f *Fetcher) fetchToBufferWithCache(u url.URL, opts FetchOptions) ([]byte, error) {
+	key := f.cacheKey(u, opts)
+	if key == "" {
+		return f.fetchToBufferWithPeers(u, opts)
+	}
+	if data, ok := f.readCacheEntryToBuffer(key, opts); ok {
+		f.Logger.Info("using cached copy of %s", RedactedURL(u))
+		f.shareCacheEntry(key, u, opts)
+		return data, nil
+	}
+	data, err := f.fetchToBufferWithPeers(u, opts)
+	if err != nil {
+		return nil, err
+	}
+	f.writeCacheEntry(key, bytes.NewReader(data))
+	f.shareCacheEntry(key, u, opts)
+	return data, nil
+}
+
+// FetchToCache fetches the resource into the cache, unless it's already
+// there, so that later fetches of the resource don't need the network. It
+// does nothing if the resource can't be cached.
+func (f *Fetcher) FetchToCache(u url.URL, opts FetchOptions) error {
+	key := f.cacheKey(u, opts)
+	if key == "" {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(f.CacheDir, key)); err == nil {
+		f.shareCacheEntry(key, u, opts)
+		return nil
+	}
+	if err := os.MkdirAll(f.CacheDir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.CacheDir, "tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	if f.recording() {
+		opts.reportHash = sha512.New()
+	}
+	// the URL and options the resource was fetched with
+	var source url.URL
+	var sourceOpts FetchOptions
+	err = f.fetchSources(u, opts, func(u url.URL, opts FetchOptions) error {
+		if err := f.fetchWithPeers(u, tmp, opts); err != nil {
+			f.resetFile(tmp)
+			return err
+		}
+		f.recordFetch(u, opts)
+		source, sourceOpts = u, opts
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(f.CacheDir, key)); err != nil {
+		return err
+	}
+	f.shareCacheEntry(key, source, sourceOpts)
+	return nil
+}
+
+// readCacheEntry copies the cache entry into dest, returning whether it
+// was found and matched the expected sum. dest is left empty otherwise.
+func (f *Fetcher) readCacheEntry(key string, dest *os.File, opts FetchOptions) bool {
+	entry, err := os.Open(filepath.Join(f.CacheDir, key))
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = entry.Close()
+	}()
+	hashers := opts.contentHashers()
+	if _, err := io.Copy(io.MultiWriter(append([]io.Writer{dest}, hashers...)...), entry); err == nil && bytes.Equal(opts.Hash.Sum(nil), opts.ExpectedSum) {
+		return true
+	}
+	f.Logger.Warning("discarding invalid cache entry %s", key)
+	_ = os.Remove(entry.Name())
+	f.resetFile(dest)
+	return false
+}
+
+// readCacheEntryToBuffer returns the contents of the cache entry, and
+// whether it was found and matched the expected sum.
+func (f *Fetcher) readCacheEntryToBuffer(key string, opts FetchOptions) ([]byte, bool) {
+	path := filepath.Join(f.CacheDir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	opts.Hash.Reset()
+	_, _ = opts.Hash.Write(data)
+	if !bytes.Equal(opts.Hash.Sum(nil), opts.ExpectedSum) {
+		f.Logger.Warning("discarding invalid cache entry %s", key)
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return data, true
+}
+
+// writeCacheEntry adds the contents of src to the cache. Failures are
+// logged rather than returned, since the resource was still fetched.
+func (f *Fetcher) writeCacheEntry(key string, src io.ReadSeeker) {
+	if err := f.storeCacheEntry(key, src); err != nil {
+		f.Logger.Warning("failed to cache %s: %v", key, err)
+	}
+}
+
+func (f *Fetcher) storeCacheEntry(key string, src io.ReadSeeker) error {
+	if err := os.MkdirAll(f.CacheDir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.CacheDir, "tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	// leave the cursor at the end, as after the fetch
+	if _, err := io.Copy(tmp, src); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %v", tmp.Name(), err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(f.CacheDir, key))
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCache(t *testing.T) {
+	contents := []byte("hello world\n")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(contents)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL + "/file")
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger:   &logger,
+		CacheDir: filepath.Join(t.TempDir(), "cache"),
+	}
+	sum := sha256.Sum256(contents)
+	opts := func() FetchOptions {
+		return hashOptions(t, "sha256-"+hex.EncodeToString(sum[:]))
+	}
+	fetch := func() []byte {
+		dest, err := os.CreateTemp(t.TempDir(), "fetch")
+		require.NoError(t, err)
+		defer func() {
+			_ = dest.Close()
+		}()
+		require.NoError(t, f.Fetch(*u, dest, opts()))
+		_, err = dest.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+		data, err := io.ReadAll(dest)
+		require.NoError(t, err)
+		return data
+	}
+
+	// populated by the first fetch and used by later ones
+	assert.Equal(t, contents, fetch())
+	assert.Equal(t, contents, fetch())
+	data, err := f.FetchToBuffer(*u, opts())
+	require.NoError(t, err)
+	assert.Equal(t, contents, data)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// usable offline
+	f.Offline = true
+	assert.Equal(t, contents, fetch())
+	f.Offline = false
+
+	// corrupt entries are replaced
+	entries, err := os.ReadDir(f.CacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(f.CacheDir, entries[0].Name()), []byte("corrupt"), 0600))
+	assert.Equal(t, contents, fetch())
+	assert.Equal(t, int32(2), requests.Load())
+
+	// resources without a hash aren't cached
+	dest, err := os.CreateTemp(t.TempDir(), "fetch")
+	require.NoError(t, err)
+	defer func() {
+		_ = dest.Close()
+	}()
+	require.NoError(t, f.Fetch(*u, dest, FetchOptions{}))
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestFetchToCache(t *testing.T) {
+	contents := []byte("hello world\n")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(contents)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL + "/file")
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger:   &logger,
+		CacheDir: filepath.Join(t.TempDir(), "cache"),
+	}
+	sum := sha256.Sum256(contents)
+	hash := "sha256-" + hex.EncodeToString(sum[:])
+	require.NoError(t, f.FetchToCache(*u, hashOptions(t, hash)))
+	require.NoError(t, f.FetchToCache(*u, hashOptions(t, hash)))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// a resource failing verification isn't cached
+	bad := sha256.Sum256([]byte("other"))
+	assert.Error(t, f.FetchToCache(*u, hashOptions(t, "sha256-"+hex.EncodeToString(bad[:]))))
+	entries, err := os.ReadDir(f.CacheDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	f.Offline = true
+	data, err := f.FetchToBuffer(*u, hashOptions(t, hash))
+	require.NoError(t, err)
+	assert.Equal(t, contents, data)
+}
+
+func TestFetchCacheHashFunctions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger:   &logger,
+		CacheDir: filepath.Join(t.TempDir(), "cache"),
+	}
+	// the SHA-256 and SHA3-256 sums of different resources are the same
+	// length, so their cache entries must be told apart by function
+	sha2Sum := sha256.Sum256([]byte("/sha2"))
+	sha3Sum := sha3.Sum256([]byte("/sha3"))
+	sha2Hash := "sha256-" + hex.EncodeToString(sha2Sum[:])
+	sha3Hash := "sha3-256-" + hex.EncodeToString(sha3Sum[:])
+	for _, resource := range []struct{ path, hash string }{
+		{"/sha2", sha2Hash},
+		{"/sha3", sha3Hash},
+	} {
+		u, err := url.Parse(server.URL + resource.path)
+		require.NoError(t, err)
+		require.NoError(t, f.FetchToCache(*u, hashOptions(t, resource.hash)))
+	}
+	entries, err := os.ReadDir(f.CacheDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{sha2Hash, sha3Hash}, names)
+
+	// both are used from the cache
+	f.Offline = true
+	for _, resource := range []struct{ path, hash string }{
+		{"/sha2", sha2Hash},
+		{"/sha3", sha3Hash},
+	} {
+		u, err := url.Parse(server.URL + resource.path)
+		require.NoError(t, err)
+		data, err := f.FetchToBuffer(*u, hashOptions(t, resource.hash))
+		require.NoError(t, err)
+		assert.Equal(t, resource.path, string(data))
+	}
+
+	// a cache entry with the same sum, but verified with another
+	// function, doesn't match
+	sameSum := "sha3-256-" + hex.EncodeToString(sha2Sum[:])
+	u, err := url.Parse(server.URL + "/sha2")
+	require.NoError(t, err)
+	_, err = f.FetchToBuffer(*u, hashOptions(t, sameSum))
+	assert.ErrorIs(t, err, ErrNeedNet)
+	_, err = os.Stat(filepath.Join(f.CacheDir, sha2Hash))
+	assert.NoError(t, err)
+}
+
+// hashOptions returns options verifying a resource against a hash
+// specifier, such as "sha256-<sum>", as for a config's verification.
+func hashOptions(t *testing.T, hash string) FetchOptions {
+	verify := types.Verification{Hash: &hash}
+	hasher, err := util.GetHasher(verify)
+	require.NoError(t, err)
+	sum, err := util.ExpectedSum(verify)
+	require.NoError(t, err)
+	return FetchOptions{Hash: hasher, ExpectedSum: sum}
+}
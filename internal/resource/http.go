@@ -15,13 +15,16 @@
 package resource
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/hex"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -30,6 +33,7 @@ import (
 	"time"
 
 	ignerrors "github.com/coreos/ignition/v2/config/shared/errors"
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/ignition/v2/internal/earlyrand"
 	"github.com/coreos/ignition/v2/internal/log"
@@ -42,16 +46,14 @@ import (
 )
 
 const (
-	initialBackoff = 200 * time.Millisecond
-	maxBackoff     = 5 * time.Second
-
 	defaultHttpResponseHeaderTimeout = 10
 	defaultHttpTotalTimeout          = 0
 )
 
 var (
-	ErrTimeout         = errors.New("unable to fetch resource in time")
-	ErrPEMDecodeFailed = errors.New("unable to decode PEM block")
+	ErrTimeout            = errors.New("unable to fetch resource in time")
+	ErrPEMDecodeFailed    = errors.New("unable to decode PEM block")
+	ErrPublicKeyNotPinned = errors.New("server certificate chain doesn't match a pinned public key")
 )
 
 // HttpClient is a simple wrapper around the Go HTTP client that standardizes
@@ -60,12 +62,18 @@ type HttpClient struct {
 	client  *http.Client
 	logger  *log.Logger
 	timeout time.Duration
+	retry   retryPolicy
 
 	transport *http.Transport
 	cas       map[string][]byte
+	vault     *vaultSession
+	limits    *fetchLimits
+	peers     *peerSession
+	shares    *peerShares
+	proxy     *proxySelector
 }
 
-func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.Resource, proxy types.Proxy) error {
+func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, retry types.Retry, tlsCfg types.TLS, proxy types.Proxy) error {
 	if f.client == nil {
 		if err := f.newHttpClient(); err != nil {
 			return err
@@ -88,37 +96,151 @@ func (f *Fetcher) UpdateHttpTimeoutsAndCAs(timeouts types.Timeouts, cas []types.
 	f.client.transport.ResponseHeaderTimeout = time.Duration(responseHeader) * time.Second
 	f.client.client.Transport = f.client.transport
 
-	// Update proxy
-	f.client.transport.Proxy = func(req *http.Request) (*url.URL, error) {
-		return proxyFuncFromIgnitionConfig(proxy)(req.URL)
+	// Update retry policy
+	f.client.retry = newRetryPolicy(retry)
+
+	// Update proxy. Until the TLS settings are in place, only the static
+	// settings apply, since fetching the proxy credentials and auto-config
+	// file may need them.
+	reloadProxy := !f.client.proxy.matches(proxy)
+	if reloadProxy {
+		f.setProxy(newProxySelector(proxy, f.Logger))
 	}
-	f.client.client.Transport = f.client.transport
 
-	// Update CAs
-	if len(cas) == 0 {
+	// Update CAs, client certificate, and TLS policy
+	if err := f.updateTLS(tlsCfg); err != nil {
+		return err
+	}
+
+	if reloadProxy {
+		return f.loadProxyResources(f.client.proxy)
+	}
+	return nil
+}
+
+// updateTLS applies the CAs, client certificate, and TLS policy of the
+// config to the transport.
+func (f *Fetcher) updateTLS(tlsCfg types.TLS) error {
+	hasClientCert := cutil.NotEmpty(tlsCfg.ClientCertificate.Source)
+	hasPolicy := len(tlsCfg.PinnedPublicKeys) > 0 || cutil.NotEmpty(tlsCfg.MinVersion) || len(tlsCfg.CipherSuites) > 0
+	if len(tlsCfg.CertificateAuthorities) == 0 && !hasClientCert && !hasPolicy {
 		return nil
 	}
+	tlsConfig := &tls.Config{}
+	if f.client.transport.TLSClientConfig != nil {
+		tlsConfig = f.client.transport.TLSClientConfig.Clone()
+	}
 
-	pool, err := x509.SystemCertPool()
-	if err != nil {
-		f.Logger.Err("Unable to read system certificate pool: %s", err)
+	if len(tlsCfg.CertificateAuthorities) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			f.Logger.Err("Unable to read system certificate pool: %s", err)
+			return err
+		}
+
+		for _, ca := range tlsCfg.CertificateAuthorities {
+			cablob, err := f.getTLSBlob(ca)
+			if err != nil {
+				return err
+			}
+			if err := f.parseCABundle(cablob, ca, pool); err != nil {
+				f.Logger.Err("Unable to parse CA bundle: %s", err)
+				return err
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if hasClientCert {
+		cert, err := f.getClientCertificate(tlsCfg)
+		if err != nil {
+			f.Logger.Err("Unable to load client certificate: %s", err)
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if err := applyTLSPolicy(tlsConfig, tlsCfg); err != nil {
 		return err
 	}
 
-	for _, ca := range cas {
-		cablob, err := f.getCABlob(ca)
+	f.client.transport.TLSClientConfig = tlsConfig
+	return nil
+}
+
+// applyTLSPolicy sets the public key pins, minimum version, and cipher
+// suites of the config on tlsConfig.
+func applyTLSPolicy(tlsConfig *tls.Config, tlsCfg types.TLS) error {
+	tlsConfig.VerifyConnection = nil
+	if len(tlsCfg.PinnedPublicKeys) > 0 {
+		var pins [][]byte
+		for _, pin := range tlsCfg.PinnedPublicKeys {
+			sum, err := cutil.ParsePublicKeyPin(pin)
+			if err != nil {
+				return err
+			}
+			pins = append(pins, sum)
+		}
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPinnedPublicKey(cs, pins)
+		}
+	}
+
+	if cutil.NotEmpty(tlsCfg.MinVersion) {
+		version, err := cutil.ParseTLSVersion(*tlsCfg.MinVersion)
 		if err != nil {
 			return err
 		}
-		if err := f.parseCABundle(cablob, ca, pool); err != nil {
-			f.Logger.Err("Unable to parse CA bundle: %s", err)
+		tlsConfig.MinVersion = version
+	}
+
+	tlsConfig.CipherSuites = nil
+	for _, name := range tlsCfg.CipherSuites {
+		id, err := cutil.ParseCipherSuite(name)
+		if err != nil {
 			return err
 		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
 	}
-	f.client.transport.TLSClientConfig = &tls.Config{RootCAs: pool}
 	return nil
 }
 
+// verifyPinnedPublicKey checks that a certificate in one of the verified
+// chains of the connection has a public key matching one of the pins.
+func verifyPinnedPublicKey(cs tls.ConnectionState, pins [][]byte) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pin := range pins {
+				if bytes.Equal(sum[:], pin) {
+					return nil
+				}
+			}
+		}
+	}
+	return ErrPublicKeyNotPinned
+}
+
+// getClientCertificate fetches the client certificate and key, decrypting
+// the key if needed.
+func (f *Fetcher) getClientCertificate(tlsCfg types.TLS) (tls.Certificate, error) {
+	certBlob, err := f.getTLSBlob(tlsCfg.ClientCertificate)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyBlob, err := f.getTLSBlob(tlsCfg.ClientKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if tlsCfg.ClientKey.Encryption.IsPresent() {
+		keyBlob, err = util.Decrypt(tlsCfg.ClientKey.Encryption, keyBlob)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("decrypting client key: %v", err)
+		}
+	}
+	return tls.X509KeyPair(certBlob, keyBlob)
+}
+
 // parseCABundle parses a CA bundle which includes multiple CAs.
 func (f *Fetcher) parseCABundle(cablob []byte, ca types.Resource, pool *x509.CertPool) error {
 	for len(cablob) > 0 {
@@ -139,83 +261,148 @@ func (f *Fetcher) parseCABundle(cablob []byte, ca types.Resource, pool *x509.Cer
 	return nil
 }
 
-func (f *Fetcher) getCABlob(ca types.Resource) ([]byte, error) {
+// getTLSBlob fetches a CA bundle, client certificate, client key, or proxy
+// resource.
+func (f *Fetcher) getTLSBlob(ca types.Resource) ([]byte, error) {
 	// this is also already checked at validation time
 	if ca.Source == nil {
-		f.Logger.Crit("invalid CA: %v", ignerrors.ErrSourceRequired)
+		f.Logger.Crit("invalid TLS resource: %v", ignerrors.ErrSourceRequired)
 		return nil, ignerrors.ErrSourceRequired
 	}
 	if blob, ok := f.client.cas[*ca.Source]; ok {
 		return blob, nil
 	}
-	u, err := url.Parse(*ca.Source)
+	cablob, err := f.fetchResource(ca)
 	if err != nil {
-		f.Logger.Crit("Unable to parse CA URL: %s", err)
+		f.Logger.Err("Unable to fetch TLS resource (%s): %s", *ca.Source, err)
 		return nil, err
 	}
-	hasher, err := util.GetHasher(ca.Verification)
+	f.client.cas[*ca.Source] = cablob
+	return cablob, nil
+
+}
+
+// FetchResource fetches a resource into memory, verifying its hash and
+// decrypting it if the config specifies.
+func (f *Fetcher) FetchResource(res types.Resource) ([]byte, error) {
+	// this is also already checked at validation time
+	if res.Source == nil {
+		return nil, ignerrors.ErrSourceRequired
+	}
+	blob, err := f.fetchResource(res)
 	if err != nil {
-		f.Logger.Crit("Unable to get hasher: %s", err)
 		return nil, err
 	}
-
-	var expectedSum []byte
-	if hasher != nil {
-		// explicitly ignoring the error here because the config should already
-		// be validated by this point
-		_, expectedSumString, _ := util.HashParts(ca.Verification)
-		expectedSum, err = hex.DecodeString(expectedSumString)
+	if res.Encryption.IsPresent() {
+		blob, err = util.Decrypt(res.Encryption, blob)
 		if err != nil {
-			f.Logger.Crit("Error parsing verification string %q: %v", expectedSumString, err)
-			return nil, err
+			return nil, fmt.Errorf("decrypting resource: %v", err)
 		}
 	}
+	return blob, nil
+}
+
+// fetchResource fetches a resource into memory with the fetch options the
+// config specifies. It's used for TLS keys, proxy and Vault credentials, and
+// password hashes, so the resource is marked sensitive.
+func (f *Fetcher) fetchResource(res types.Resource) ([]byte, error) {
+	u, err := url.Parse(*res.Source)
+	if err != nil {
+		f.Logger.Crit("Unable to parse resource URL: %s", err)
+		return nil, err
+	}
+	hasher, err := util.GetHasher(res.Verification)
+	if err != nil {
+		f.Logger.Crit("Unable to get hasher: %s", err)
+		return nil, err
+	}
+
+	expectedSum, err := util.ExpectedSum(res.Verification)
+	if err != nil {
+		f.Logger.Crit("Error verifying resource: %v", err)
+		return nil, err
+	}
+	transport := util.TransportVerification(res.Verification)
+	transportHasher, err := util.GetHasher(transport)
+	if err != nil {
+		f.Logger.Crit("Unable to get hasher: %s", err)
+		return nil, err
+	}
+	expectedTransportSum, err := util.ExpectedSum(transport)
+	if err != nil {
+		f.Logger.Crit("Error verifying resource: %v", err)
+		return nil, err
+	}
 
 	var headers http.Header
-	if len(ca.HTTPHeaders) > 0 {
-		headers, err = ca.HTTPHeaders.Parse()
+	if len(res.HTTPHeaders) > 0 {
+		headers, err = res.HTTPHeaders.Parse()
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	var compression string
-	if ca.Compression != nil {
-		compression = *ca.Compression
+	if res.Compression != nil {
+		compression = *res.Compression
+	}
+
+	mirrors, err := ParseMirrors(res.Sources)
+	if err != nil {
+		f.Logger.Crit("Unable to parse resource URL: %s", err)
+		return nil, err
 	}
 
-	cablob, err := f.FetchToBuffer(*u, FetchOptions{
+	return f.FetchToBuffer(*u, FetchOptions{
 		Hash:        hasher,
 		Headers:     headers,
 		ExpectedSum: expectedSum,
 		Compression: compression,
-	})
-	if err != nil {
-		f.Logger.Err("Unable to fetch CA (%s): %s", u, err)
-		return nil, err
-	}
-	f.client.cas[*ca.Source] = cablob
-	return cablob, nil
+		Auth:        res.Auth,
+		Mirrors:     mirrors,
+		Sensitive:   true,
 
+		TransportHash:        transportHasher,
+		ExpectedTransportSum: expectedTransportSum,
+	})
 }
 
 // RewriteCAsWithDataUrls will modify the passed in slice of CA references to
 // contain the actual CA file via a dataurl in their source field.
 func (f *Fetcher) RewriteCAsWithDataUrls(cas []types.Resource) error {
-	for i, ca := range cas {
-		blob, err := f.getCABlob(ca)
-		if err != nil {
+	for i := range cas {
+		if err := f.rewriteWithDataUrl(&cas[i]); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// Clean HTTP headers
-		cas[i].HTTPHeaders = nil
-		// the rewrite wipes the compression
-		cas[i].Compression = nil
+// RewriteClientCertificateWithDataUrls is like RewriteCAsWithDataUrls for
+// the client certificate and key. The key is left encrypted, if it was.
+func (f *Fetcher) RewriteClientCertificateWithDataUrls(tlsCfg *types.TLS) error {
+	if cutil.NilOrEmpty(tlsCfg.ClientCertificate.Source) {
+		return nil
+	}
+	if err := f.rewriteWithDataUrl(&tlsCfg.ClientCertificate); err != nil {
+		return err
+	}
+	return f.rewriteWithDataUrl(&tlsCfg.ClientKey)
+}
 
-		encoded := dataurl.EncodeBytes(blob)
-		cas[i].Source = &encoded
+func (f *Fetcher) rewriteWithDataUrl(res *types.Resource) error {
+	blob, err := f.getTLSBlob(*res)
+	if err != nil {
+		return err
 	}
+
+	// Clean HTTP headers
+	res.HTTPHeaders = nil
+	// the rewrite wipes the compression
+	res.Compression = nil
+
+	encoded := dataurl.EncodeBytes(blob)
+	res.Source = &encoded
 	return nil
 }
 
@@ -290,42 +477,93 @@ func (f *Fetcher) newHttpClient() error {
 		return err
 	}
 
+	// We do not want to redirect HTTP headers
+	defaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		req.Header = make(http.Header)
+		return nil
+	}
+
 	f.client = &HttpClient{
 		client:    defaultClient,
 		logger:    f.Logger,
 		timeout:   time.Duration(defaultHttpTotalTimeout) * time.Second,
+		retry:     defaultRetryPolicy(),
 		transport: defaultClient.Transport.(*http.Transport),
 		cas:       make(map[string][]byte),
+		shares:    &peerShares{keys: make(map[string]bool)},
 	}
 	return nil
 }
 
-func shouldRetryHttp(statusCode int, opts FetchOptions) bool {
-	// we always retry 500+
-	if statusCode >= 500 {
-		return true
-	}
-
-	for _, retryCode := range opts.RetryCodes {
-		if statusCode == retryCode {
-			return true
-		}
-	}
-
-	return false
+// Clone returns a copy of the fetcher which logs to logger, for fetching
+// concurrently with f. The copy has its own HTTP client, since requests
+// modify the transport and cache TLS resources, but shares the peer, Vault,
+// and limit state, which are safe for concurrent use.
+func (f Fetcher) Clone(logger *log.Logger) Fetcher {
+	f.Logger = logger
+	if f.client == nil {
+		return f
+	}
+	c := *f.client
+	c.logger = logger
+	c.cas = maps.Clone(c.cas)
+	c.transport = c.transport.Clone()
+	client := *c.client
+	client.Transport = c.transport
+	c.client = &client
+	f.client = &c
+	if c.proxy != nil {
+		proxy := *c.proxy
+		proxy.logger = logger
+		f.setProxy(&proxy)
+	}
+	return f
 }
 
 // httpReaderWithHeader performs an HTTP request on the provided URL with the
 // provided request header & method and returns the response body Reader, HTTP
 // status code, a cancel function for the result's context, and error (if any).
 // By default, User-Agent is added to the header but this can be overridden.
+// If reading the body fails partway through, the download is resumed with a
+// Range request when the server supports it.
 func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.ReadCloser, int, context.CancelFunc, error) {
+	ctx, cancelFn := c.requestContext()
+	resp, err := c.httpResponseWithContext(ctx, opts, url)
+	if err != nil {
+		return nil, 0, cancelFn, err
+	}
+	body := c.resumable(ctx, opts, url, resp)
+	return body, resp.StatusCode, cancelFn, nil
+}
+
+// httpResponseWithHeader is like httpReaderWithHeader, but returns the
+// whole response, for callers which need its headers.
+func (c HttpClient) httpResponseWithHeader(opts FetchOptions, url string) (*http.Response, context.CancelFunc, error) {
+	ctx, cancelFn := c.requestContext()
+	resp, err := c.httpResponseWithContext(ctx, opts, url)
+	return resp, cancelFn, err
+}
+
+// requestContext returns the context for a request and the response body,
+// which is bounded by the total timeout, if one is set.
+func (c HttpClient) requestContext() (context.Context, context.CancelFunc) {
+	if c.timeout != 0 {
+		return context.WithTimeout(context.Background(), c.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// httpResponseWithContext performs the request, with retries, in ctx.
+func (c HttpClient) httpResponseWithContext(ctx context.Context, opts FetchOptions, url string) (*http.Response, error) {
 	if opts.HTTPVerb == "" {
 		opts.HTTPVerb = "GET"
 	}
 	req, err := http.NewRequest(opts.HTTPVerb, url, nil)
 	if err != nil {
-		return nil, 0, nil, err
+		return nil, err
+	}
+	if opts.body != nil {
+		req.ContentLength = int64(len(opts.body))
 	}
 
 	req.Header.Set("User-Agent", "Ignition/"+version.Raw)
@@ -337,37 +575,48 @@ func (c HttpClient) httpReaderWithHeader(opts FetchOptions, url string) (io.Read
 		}
 	}
 
-	ctx, cancelFn := context.WithCancel(context.Background())
-	if c.timeout != 0 {
-		cancelFn()
-		ctx, cancelFn = context.WithTimeout(context.Background(), c.timeout)
-	}
-
-	duration := initialBackoff
 	for attempt := 1; ; attempt++ {
 		c.logger.Info("%s %s: attempt #%d", opts.HTTPVerb, url, attempt)
+		if opts.body != nil {
+			// each attempt consumes the request body
+			req.Body = io.NopCloser(bytes.NewReader(opts.body))
+		}
+		if opts.authorize != nil {
+			if err := opts.authorize(req); err != nil {
+				return nil, err
+			}
+		}
 		resp, err := c.client.Do(req.WithContext(ctx))
 
 		if err == nil {
 			c.logger.Info("%s result: %s", opts.HTTPVerb, http.StatusText(resp.StatusCode))
-			if !shouldRetryHttp(resp.StatusCode, opts) {
-				return resp.Body, resp.StatusCode, cancelFn, nil
+			if !c.retry.retryStatus(resp.StatusCode, opts) {
+				return resp, nil
 			}
-			_ = resp.Body.Close()
 		} else {
 			c.logger.Info("%s error: %v", opts.HTTPVerb, err)
+			if !c.retry.retryError(err) {
+				return nil, err
+			}
+		}
+
+		duration, ok := c.retry.backoff(attempt)
+		if !ok {
+			// Out of attempts; return the last result
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+		if err == nil {
+			_ = resp.Body.Close()
 		}
 
 		// Wait before next attempt or exit if we timeout while waiting
 		select {
 		case <-time.After(duration):
 		case <-ctx.Done():
-			return nil, 0, cancelFn, ErrTimeout
-		}
-
-		duration = duration * 2
-		if duration > maxBackoff {
-			duration = maxBackoff
+			return nil, ErrTimeout
 		}
 	}
 }
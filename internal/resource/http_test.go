@@ -0,0 +1,216 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+)
+
+// makeClientCertificate returns a self-signed client certificate and its
+// key, in PEM format.
+func makeClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ignition"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestClientCertificate(t *testing.T) {
+	certPEM, keyPEM := makeClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(certPEM))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	tlsCfg := types.TLS{
+		CertificateAuthorities: []types.Resource{{Source: util.StrToPtr(dataurl.EncodeBytes(serverCA))}},
+	}
+	retry := types.Retry{MaxAttempts: util.IntToPtr(1)}
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, tlsCfg, types.Proxy{}))
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.Error(t, err)
+
+	tlsCfg.ClientCertificate.Source = util.StrToPtr(dataurl.EncodeBytes(certPEM))
+	tlsCfg.ClientKey.Source = util.StrToPtr(dataurl.EncodeBytes(keyPEM))
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, tlsCfg, types.Proxy{}))
+	data, err := f.FetchToBuffer(*u, FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+
+	// a key not matching the certificate is rejected
+	_, otherKeyPEM := makeClientCertificate(t)
+	tlsCfg.ClientKey.Source = util.StrToPtr(dataurl.EncodeBytes(otherKeyPEM))
+	assert.Error(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, tlsCfg, types.Proxy{}))
+}
+
+func TestTLSPolicy(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	sum := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+	other := sha256.Sum256(nil)
+	otherPin := "sha256/" + base64.StdEncoding.EncodeToString(other[:])
+	retry := types.Retry{MaxAttempts: util.IntToPtr(1)}
+
+	tests := []struct {
+		name string
+		tls  types.TLS
+		err  bool
+	}{
+		{
+			name: "pinned",
+			tls:  types.TLS{PinnedPublicKeys: []string{otherPin, pin}},
+		},
+		{
+			name: "not pinned",
+			tls:  types.TLS{PinnedPublicKeys: []string{otherPin}},
+			err:  true,
+		},
+		{
+			name: "minimum version",
+			tls:  types.TLS{MinVersion: util.StrToPtr("1.3")},
+			err:  true,
+		},
+		{
+			name: "cipher suites",
+			tls:  types.TLS{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+		},
+		{
+			name: "no common cipher suite",
+			tls:  types.TLS{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}},
+			err:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.tls.CertificateAuthorities = []types.Resource{{Source: util.StrToPtr(dataurl.EncodeBytes(serverCA))}}
+			logger := log.New(true)
+			f := Fetcher{Logger: &logger}
+			require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, test.tls, types.Proxy{}))
+			_, err := f.FetchToBuffer(*u, FetchOptions{})
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFetcherClone(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	tlsCfg := types.TLS{
+		CertificateAuthorities: []types.Resource{{Source: util.StrToPtr(dataurl.EncodeBytes(serverCA))}},
+	}
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, types.Retry{}, tlsCfg, types.Proxy{}))
+
+	// fetches which set the local port modify the transport, and the
+	// original logger is still in use, so run with -race to check that the
+	// clones don't share them
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		workerLogger := logger.Clone()
+		clone := f.Clone(&workerLogger)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = clone.FetchToBuffer(*u, FetchOptions{
+				LocalPort: func() int { return 0 },
+			})
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		logger.PushPrefix("main")
+		logger.PopPrefix()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// the clones inherit the CAs
+	data, err := f.FetchToBuffer(*u, FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+}
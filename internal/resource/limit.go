@@ -0,0 +1,167 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+)
+
+// fetchLimits bounds the bandwidth and concurrency of downloads. They're
+// shared by every fetch made with the same HttpClient, so the limits apply
+// to the node as a whole.
+type fetchLimits struct {
+	// slots holds a token for each download in progress, or is nil if
+	// there's no limit on concurrent downloads.
+	slots chan struct{}
+	// bandwidth paces reads from every download, or is nil if there's no
+	// limit on bandwidth.
+	bandwidth *bandwidthLimiter
+}
+
+// UpdateNetwork sets the peers from which to fetch resources, and the
+// bandwidth and concurrency limits applied to http(s), s3, gs, Azure Blob,
+// OCI layer, and peer downloads. Limits are only replaced if they changed,
+// so downloads in progress keep their slots.
+func (f *Fetcher) UpdateNetwork(network types.Network) error {
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return err
+		}
+	}
+	if err := f.updatePeers(network.Peers); err != nil {
+		return err
+	}
+	limits := f.client.limits
+	if limits == nil {
+		limits = &fetchLimits{}
+	} else {
+		copied := *limits
+		limits = &copied
+	}
+	if n := network.MaxConcurrentFetches; n == nil {
+		limits.slots = nil
+	} else if limits.slots == nil || cap(limits.slots) != *n {
+		limits.slots = make(chan struct{}, *n)
+	}
+	if n := network.MaxBandwidth; n == nil {
+		limits.bandwidth = nil
+	} else if limits.bandwidth == nil || limits.bandwidth.rate != *n {
+		limits.bandwidth = &bandwidthLimiter{rate: *n}
+	}
+	if limits.slots == nil && limits.bandwidth == nil {
+		limits = nil
+	}
+	f.client.limits = limits
+	return nil
+}
+
+// limits returns the download limits, or nil if there are none.
+func (f *Fetcher) limits() *fetchLimits {
+	if f.client == nil {
+		return nil
+	}
+	return f.client.limits
+}
+
+// acquire waits for a download slot, and returns a function which releases
+// it.
+func (l *fetchLimits) acquire() func() {
+	if l == nil || l.slots == nil {
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	return func() {
+		<-l.slots
+	}
+}
+
+// reader paces reads from r to the bandwidth limit.
+func (l *fetchLimits) reader(r io.Reader) io.Reader {
+	if l == nil || l.bandwidth == nil {
+		return r
+	}
+	return &limitedReader{r: r, limiter: l.bandwidth}
+}
+
+// s3target paces writes to dest to the bandwidth limit. The S3 downloader
+// writes parts to dest as they arrive, so this paces the download.
+func (l *fetchLimits) s3target(dest s3target) s3target {
+	if l == nil || l.bandwidth == nil {
+		return dest
+	}
+	return &limitedS3Target{s3target: dest, limiter: l.bandwidth}
+}
+
+// bandwidthLimiter schedules transfers so that, on average, no more than
+// rate bytes are transferred per second.
+type bandwidthLimiter struct {
+	rate int
+
+	mu sync.Mutex
+	// next is when the transfers scheduled so far will have completed at
+	// the limited rate.
+	next time.Time
+}
+
+// wait accounts for n transferred bytes, and sleeps until the transfer
+// would have completed at the limited rate.
+func (b *bandwidthLimiter) wait(n int) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	b.next = b.next.Add(time.Duration(float64(n) / float64(b.rate) * float64(time.Second)))
+	delay := b.next.Sub(now)
+	b.mu.Unlock()
+	time.Sleep(delay)
+}
+
+// maxChunk returns the most bytes to transfer at once, so that pacing stays
+// smooth when the limit is low.
+func (b *bandwidthLimiter) maxChunk(n int) int {
+	if chunk := max(b.rate/10, 1); n > chunk {
+		return chunk
+	}
+	return n
+}
+
+type limitedReader struct {
+	r       io.Reader
+	limiter *bandwidthLimiter
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p[:l.limiter.maxChunk(len(p))])
+	l.limiter.wait(n)
+	return n, err
+}
+
+type limitedS3Target struct {
+	s3target
+	limiter *bandwidthLimiter
+}
+
+func (l *limitedS3Target) WriteAt(p []byte, off int64) (int, error) {
+	l.limiter.wait(len(p))
+	return l.s3target.WriteAt(p, off)
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxConcurrentFetches(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	require.NoError(t, f.UpdateNetwork(types.Network{MaxConcurrentFetches: util.IntToPtr(2)}))
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := f.FetchToBuffer(*u, FetchOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", string(data))
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	// removing the limit drops the limits entirely
+	require.NoError(t, f.UpdateNetwork(types.Network{}))
+	assert.Nil(t, f.limits())
+}
+
+func TestMaxBandwidth(t *testing.T) {
+	contents := bytes.Repeat([]byte("x"), 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(contents)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	require.NoError(t, f.UpdateNetwork(types.Network{MaxBandwidth: util.IntToPtr(4000)}))
+
+	start := time.Now()
+	data, err := f.FetchToBuffer(*u, FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, contents, data)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestBandwidthLimiterChunks(t *testing.T) {
+	b := &bandwidthLimiter{rate: 100}
+	assert.Equal(t, 10, b.maxChunk(32*1024))
+	assert.Equal(t, 5, b.maxChunk(5))
+	b = &bandwidthLimiter{rate: 5}
+	assert.Equal(t, 1, b.maxChunk(32*1024))
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/coreos/ignition/v2/internal/distro"
+	"github.com/coreos/ignition/v2/internal/util"
+)
+
+// blockDeviceTimeout is how long to wait for the device of a block URL to
+// appear.
+const blockDeviceTimeout = 30 * time.Second
+
+// fetchFromFile reads the file at the path of u, a file URL. The path is
+// in the initramfs, not the target root.
+func (f *Fetcher) fetchFromFile(u url.URL, dest io.Writer, opts FetchOptions) error {
+	return f.copyLocalFile(filepath.Clean(u.Path), dest, opts)
+}
+
+// fetchFromBlockDevice reads a file from the filesystem on the block device
+// with the label given as the host of u, mounting it read-only for the
+// duration of the fetch.
+func (f *Fetcher) fetchFromBlockDevice(u url.URL, dest io.Writer, opts FetchOptions) error {
+	device := filepath.Join(distro.DiskByLabelDir(), u.Host)
+	ctx, cancel := context.WithTimeout(context.Background(), blockDeviceTimeout)
+	defer cancel()
+	for {
+		if _, err := os.Stat(device); err == nil {
+			break
+		}
+		f.Logger.Debug("device %q not found. Waiting...", device)
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return fmt.Errorf("device %q did not appear within %v", device, blockDeviceTimeout)
+		}
+	}
+
+	mnt, err := os.MkdirTemp("", "ignition-block")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer func() {
+		if err := os.Remove(mnt); err != nil {
+			f.Logger.Err("failed to remove temporary mount point %q: %v", mnt, err)
+		}
+	}()
+
+	cmd := exec.CommandContext(ctx, distro.MountCmd(), "-o", "ro", "-t", "auto", device, mnt)
+	if _, err := f.Logger.LogCmd(cmd, "mounting %q", device); err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Logger.LogOp(
+			func() error {
+				return util.UmountPath(mnt)
+			},
+			"unmounting %q at %q", device, mnt,
+		)
+	}()
+
+	return f.copyLocalFile(filepath.Join(mnt, filepath.Clean(filepath.Join("/", u.Path))), dest, opts)
+}
+
+func (f *Fetcher) copyLocalFile(path string, dest io.Writer, opts FetchOptions) error {
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	return f.decompressCopyHashAndVerify(dest, src, opts)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha256"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "payload")
+	require.NoError(t, os.WriteFile(path, []byte("hello world\n"), 0644))
+
+	logger := log.New(true)
+	// local files are available offline
+	f := Fetcher{Logger: &logger, Offline: true}
+
+	u, err := url.Parse("file://" + path)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte("hello world\n"))
+	data, err := f.FetchToBuffer(*u, FetchOptions{Hash: sha256.New(), ExpectedSum: sum[:]})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+
+	bad := sha256.Sum256(nil)
+	_, err = f.FetchToBuffer(*u, FetchOptions{Hash: sha256.New(), ExpectedSum: bad[:]})
+	assert.Error(t, err)
+
+	u, err = url.Parse("file://" + filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,396 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/internal/distro"
+)
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation      = "org.opencontainers.image.title"
+
+	// maxOCIManifestSize bounds the size of a manifest we'll read into
+	// memory.
+	maxOCIManifestSize = 4 << 20
+)
+
+var ociChallengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ociSession holds the state for requests to a registry: its base URL and
+// any credentials or token.
+type ociSession struct {
+	f       *Fetcher
+	ref     cutil.OCIReference
+	base    string
+	headers http.Header
+	// token is a bearer token obtained from the registry's token service
+	token string
+}
+
+// fetchFromOCI fetches a layer of an artifact in an OCI registry: the layer
+// whose title is ref.File, or the only layer if no file is specified. The
+// manifest and layer are verified against their digests.
+func (f *Fetcher) fetchFromOCI(u url.URL, dest io.Writer, opts FetchOptions) error {
+	ref, err := cutil.ParseOCIReference(u)
+	if err != nil {
+		return err
+	}
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return err
+		}
+	}
+	s := ociSession{
+		f:       f,
+		ref:     ref,
+		base:    ociBaseURL(ref.Registry),
+		headers: opts.Headers,
+	}
+
+	manifest, err := s.fetchManifest(opts)
+	if err != nil {
+		return err
+	}
+	layer, err := selectOCILayer(ref, manifest)
+	if err != nil {
+		return err
+	}
+	return s.fetchBlob(layer, dest, opts)
+}
+
+// ociBaseURL returns the URL of the registry API. Like container tools,
+// we use plain HTTP for registries on the loopback interface.
+func ociBaseURL(registry string) string {
+	if isLoopback(registry) {
+		return "http://" + registry + "/v2/"
+	}
+	return "https://" + registry + "/v2/"
+}
+
+// isLoopback returns whether the host of a "host[:port]" address is on the
+// loopback interface.
+func isLoopback(address string) bool {
+	host := address
+	if h, _, err := net.SplitHostPort(address); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+func (s *ociSession) fetchManifest(opts FetchOptions) (ociManifest, error) {
+	resp, cancel, err := s.get("manifests/"+s.ref.Reference, ociManifestMediaType+", "+dockerManifestMediaType, opts)
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return ociManifest{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOCIManifestSize+1))
+	if err != nil {
+		return ociManifest{}, err
+	}
+	if len(data) > maxOCIManifestSize {
+		return ociManifest{}, fmt.Errorf("manifest is larger than %d bytes", maxOCIManifestSize)
+	}
+	if s.ref.IsDigest() {
+		if err := verifyOCIDigest(s.ref.Reference, data); err != nil {
+			return ociManifest{}, fmt.Errorf("verifying manifest: %w", err)
+		}
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return ociManifest{}, fmt.Errorf("parsing manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+// selectOCILayer returns the layer of the manifest to fetch: the one titled
+// ref.File if set, otherwise the only layer.
+func selectOCILayer(ref cutil.OCIReference, manifest ociManifest) (ociDescriptor, error) {
+	if ref.File == "" {
+		if len(manifest.Layers) != 1 {
+			return ociDescriptor{}, fmt.Errorf("artifact has %d layers; specify one with the file parameter", len(manifest.Layers))
+		}
+		return manifest.Layers[0], nil
+	}
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[ociTitleAnnotation] == ref.File {
+			return layer, nil
+		}
+	}
+	return ociDescriptor{}, fmt.Errorf("artifact has no file %q", ref.File)
+}
+
+func (s *ociSession) fetchBlob(layer ociDescriptor, dest io.Writer, opts FetchOptions) error {
+	digester, err := ociDigester(layer.Digest)
+	if err != nil {
+		return err
+	}
+	limits := s.f.limits()
+	defer limits.acquire()()
+	resp, cancel, err := s.get("blobs/"+layer.Digest, "", opts)
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body := io.TeeReader(limits.reader(resp.Body), digester)
+	if err := s.f.decompressCopyHashAndVerify(dest, body, opts); err != nil {
+		return err
+	}
+	// the decompressor may stop before the end of the blob
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	if !ociDigestMatches(layer.Digest, digester.Sum(nil)) {
+		return fmt.Errorf("layer doesn't match digest %s", layer.Digest)
+	}
+	return nil
+}
+
+// get requests a path of the repository from the registry, authenticating
+// if the registry requires it. It returns an error unless the request
+// succeeds.
+func (s *ociSession) get(path, accept string, opts FetchOptions) (*http.Response, func(), error) {
+	u := s.base + s.ref.Repository + "/" + path
+	resp, cancel, err := s.do(u, accept, opts)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && s.token == "" && s.headers.Get("Authorization") == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		cancel()
+		if err := s.authenticate(challenge, opts); err != nil {
+			return nil, nil, err
+		}
+		resp, cancel, err = s.do(u, accept, opts)
+	}
+	if err != nil {
+		return nil, cancel, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, cancel, nil
+	case http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, cancel, ErrNotFound
+	default:
+		_ = resp.Body.Close()
+		return nil, cancel, ErrFailed
+	}
+}
+
+func (s *ociSession) do(u, accept string, opts FetchOptions) (*http.Response, func(), error) {
+	headers := make(http.Header)
+	if accept != "" {
+		headers.Set("Accept", accept)
+	}
+	if s.token != "" {
+		headers.Set("Authorization", "Bearer "+s.token)
+	}
+	for k, va := range s.headers {
+		for _, v := range va {
+			headers.Set(k, v)
+		}
+	}
+	requestOpts := opts
+	requestOpts.Headers = headers
+	requestOpts.HTTPVerb = ""
+	resp, cancel, err := s.f.client.httpResponseWithHeader(requestOpts, u)
+	if cancel == nil {
+		cancel = func() {}
+	}
+	return resp, cancel, err
+}
+
+// authenticate responds to an authentication challenge from the registry,
+// using credentials from the pull secret if there are any. Bearer
+// challenges are answered by requesting a token from the token service.
+func (s *ociSession) authenticate(challenge string, opts FetchOptions) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	creds, err := ociCredentials(s.ref.Registry)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if creds == "" {
+			return fmt.Errorf("registry %s requires credentials", s.ref.Registry)
+		}
+		s.headers = s.headers.Clone()
+		if s.headers == nil {
+			s.headers = make(http.Header)
+		}
+		s.headers.Set("Authorization", "Basic "+creds)
+		return nil
+	case "bearer":
+		return s.requestToken(params, creds, opts)
+	default:
+		return fmt.Errorf("registry %s requires unsupported authentication %q", s.ref.Registry, scheme)
+	}
+}
+
+func (s *ociSession) requestToken(params, creds string, opts FetchOptions) error {
+	values := map[string]string{}
+	for _, m := range ociChallengeParamRe.FindAllStringSubmatch(params, -1) {
+		values[strings.ToLower(m[1])] = m[2]
+	}
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("registry %s sent an invalid token realm %q", s.ref.Registry, values["realm"])
+	}
+	query := realm.Query()
+	if service := values["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := values["scope"]
+	if scope == "" {
+		scope = "repository:" + s.ref.Repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	headers := make(http.Header)
+	if creds != "" {
+		headers.Set("Authorization", "Basic "+creds)
+	}
+	requestOpts := opts
+	requestOpts.Headers = headers
+	requestOpts.HTTPVerb = ""
+	resp, cancel, err := s.f.client.httpResponseWithHeader(requestOpts, realm.String())
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting token for registry %s: %s", s.ref.Registry, http.StatusText(resp.StatusCode))
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOCIManifestSize)).Decode(&token); err != nil {
+		return fmt.Errorf("parsing token for registry %s: %v", s.ref.Registry, err)
+	}
+	s.token = token.Token
+	if s.token == "" {
+		s.token = token.AccessToken
+	}
+	if s.token == "" {
+		return fmt.Errorf("registry %s returned an empty token", s.ref.Registry)
+	}
+	s.f.Logger.AddSecrets(s.token)
+	return nil
+}
+
+// ociCredentials returns the base64-encoded "user:password" for the
+// registry from the pull secret, or "" if there are none.
+func ociCredentials(registry string) (string, error) {
+	data, err := os.ReadFile(distro.OCIPullSecretPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("reading pull secret: %v", err)
+	}
+	var secret struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", fmt.Errorf("parsing pull secret: %v", err)
+	}
+	for _, key := range []string{registry, "https://" + registry, "http://" + registry} {
+		auth, ok := secret.Auths[key]
+		if !ok {
+			continue
+		}
+		if auth.Auth != "" {
+			return auth.Auth, nil
+		}
+		if auth.Username != "" {
+			return base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)), nil
+		}
+	}
+	return "", nil
+}
+
+func ociDigester(digest string) (hash.Hash, error) {
+	switch {
+	case strings.HasPrefix(digest, "sha256:"):
+		return sha256.New(), nil
+	case strings.HasPrefix(digest, "sha512:"):
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported digest %q", digest)
+	}
+}
+
+func verifyOCIDigest(digest string, data []byte) error {
+	digester, err := ociDigester(digest)
+	if err != nil {
+		return err
+	}
+	_, _ = digester.Write(data)
+	if !ociDigestMatches(digest, digester.Sum(nil)) {
+		return fmt.Errorf("doesn't match digest %s", digest)
+	}
+	return nil
+}
+
+func ociDigestMatches(digest string, sum []byte) bool {
+	_, encoded, _ := strings.Cut(digest, ":")
+	return encoded == hex.EncodeToString(sum)
+}
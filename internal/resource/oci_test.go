@@ -0,0 +1,166 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ociDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newTestRegistry serves an artifact with the given files from
+// "org/artifact:v1", requiring a token obtained with user:pass.
+func newTestRegistry(t *testing.T, files map[string]string) (*httptest.Server, string) {
+	blobs := map[string][]byte{}
+	var layers []map[string]any
+	for name, contents := range files {
+		digest := ociDigest([]byte(contents))
+		blobs[digest] = []byte(contents)
+		layers = append(layers, map[string]any{
+			"mediaType":   "application/octet-stream",
+			"digest":      digest,
+			"size":        len(contents),
+			"annotations": map[string]string{ociTitleAnnotation: name},
+		})
+	}
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     ociManifestMediaType,
+		"layers":        layers,
+	})
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "test", r.URL.Query().Get("service"))
+			assert.Regexp(t, `^repository:org/\w+:pull$`, r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token": "secret-token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch path := strings.TrimPrefix(r.URL.Path, "/v2/org/artifact/"); {
+		case path == "manifests/v1" || path == "manifests/"+ociDigest(manifest):
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(path, "blobs/") && blobs[strings.TrimPrefix(path, "blobs/")] != nil:
+			_, _ = w.Write(blobs[strings.TrimPrefix(path, "blobs/")])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	secret := filepath.Join(t.TempDir(), "pull-secret.json")
+	host := strings.TrimPrefix(server.URL, "http://")
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	require.NoError(t, os.WriteFile(secret, []byte(fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, host, auth)), 0600))
+	t.Setenv("IGNITION_OCI_PULL_SECRET_PATH", secret)
+	return server, ociDigest(manifest)
+}
+
+func TestFetchFromOCI(t *testing.T) {
+	server, manifestDigest := newTestRegistry(t, map[string]string{
+		"tool":   "#!/bin/sh\n",
+		"config": "key=value\n",
+	})
+	host := strings.TrimPrefix(server.URL, "http://")
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+
+	tests := []struct {
+		source string
+		out    string
+		err    bool
+	}{
+		{source: "oci://" + host + "/org/artifact:v1?file=tool", out: "#!/bin/sh\n"},
+		{source: "oci://" + host + "/org/artifact@" + manifestDigest + "?file=config", out: "key=value\n"},
+		// multiple layers
+		{source: "oci://" + host + "/org/artifact:v1", err: true},
+		{source: "oci://" + host + "/org/artifact:v1?file=missing", err: true},
+		{source: "oci://" + host + "/org/missing:v1?file=tool", err: true},
+		// manifest doesn't match the digest
+		{source: "oci://" + host + "/org/artifact@" + ociDigest(nil) + "?file=tool", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			u, err := url.Parse(test.source)
+			require.NoError(t, err)
+			data, err := f.FetchToBuffer(*u, FetchOptions{})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.out, string(data))
+		})
+	}
+}
+
+func TestFetchFromOCISingleLayer(t *testing.T) {
+	server, _ := newTestRegistry(t, map[string]string{
+		"tool": "#!/bin/sh\n",
+	})
+	u, err := url.Parse("oci://" + strings.TrimPrefix(server.URL, "http://") + "/org/artifact:v1")
+	require.NoError(t, err)
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+
+	dest, err := os.CreateTemp(t.TempDir(), "fetch")
+	require.NoError(t, err)
+	defer func() {
+		_ = dest.Close()
+	}()
+	sum := sha256.Sum256([]byte("#!/bin/sh\n"))
+	require.NoError(t, f.Fetch(*u, dest, FetchOptions{Hash: sha256.New(), ExpectedSum: sum[:]}))
+	data, err := os.ReadFile(dest.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(data))
+}
+
+func TestOCIBaseURL(t *testing.T) {
+	assert.Equal(t, "https://quay.io/v2/", ociBaseURL("quay.io"))
+	assert.Equal(t, "https://registry.example.com:5000/v2/", ociBaseURL("registry.example.com:5000"))
+	assert.Equal(t, "http://localhost:5000/v2/", ociBaseURL("localhost:5000"))
+	assert.Equal(t, "http://127.0.0.1:5000/v2/", ociBaseURL("127.0.0.1:5000"))
+	assert.Equal(t, "http://[::1]:5000/v2/", ociBaseURL("[::1]:5000"))
+}
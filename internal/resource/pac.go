@@ -0,0 +1,539 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+// A proxy auto-config (PAC) file is a JavaScript program defining
+// FindProxyForURL(url, host). Rather than running JavaScript, Ignition
+// accepts only PAC files in which FindProxyForURL is a list of rules of the
+// form
+//
+//	if (<test> || <test> && !<test> ...) return "<proxies>";
+//
+// optionally chained with else, and ending with an unconditional
+// return "<proxies>";. Each test is a call of one of the helper functions
+// in pacFunctions, with the url and host parameters and string literals as
+// arguments.
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var ErrPACNoResult = errors.New("no rule of the proxy auto-config file applies")
+
+// pacScript is a parsed PAC file.
+type pacScript struct {
+	rules []pacRule
+	// resolver looks up host names, and is replaced in tests.
+	resolver func(host string) ([]string, error)
+}
+
+// pacRule returns result if any of the conjunctions in cond holds. A rule
+// without cond always applies.
+type pacRule struct {
+	cond   [][]pacTest
+	result string
+}
+
+// pacTest is a call of a PAC helper function, possibly negated.
+type pacTest struct {
+	negate bool
+	name   string
+	args   []pacArg
+}
+
+// pacArg is an argument of a PAC helper function: a string literal, or
+// one of the parameters of FindProxyForURL.
+type pacArg struct {
+	kind  pacArgKind
+	value string
+}
+
+type pacArgKind int
+
+const (
+	pacLiteral pacArgKind = iota
+	pacURLParam
+	pacHostParam
+)
+
+// pacFunctions are the supported PAC helper functions, by name, with the
+// number of arguments they take.
+var pacFunctions = map[string]struct {
+	args int
+	fn   func(s *pacScript, args []string) bool
+}{
+	"isPlainHostName": {1, func(s *pacScript, args []string) bool {
+		return !strings.Contains(args[0], ".")
+	}},
+	"dnsDomainIs": {2, func(s *pacScript, args []string) bool {
+		return strings.HasSuffix(strings.ToLower(args[0]), strings.ToLower(args[1]))
+	}},
+	"localHostOrDomainIs": {2, func(s *pacScript, args []string) bool {
+		host, hostdom := strings.ToLower(args[0]), strings.ToLower(args[1])
+		return host == hostdom || (!strings.Contains(host, ".") && strings.HasPrefix(hostdom, host+"."))
+	}},
+	"shExpMatch": {2, func(s *pacScript, args []string) bool {
+		return shExpMatch(args[0], args[1])
+	}},
+	"isResolvable": {1, func(s *pacScript, args []string) bool {
+		return s.resolveIPv4(args[0]) != nil
+	}},
+	"isInNet": {3, func(s *pacScript, args []string) bool {
+		ip := s.resolveIPv4(args[0])
+		pattern := net.ParseIP(args[1]).To4()
+		mask := net.ParseIP(args[2]).To4()
+		if ip == nil || pattern == nil || mask == nil {
+			return false
+		}
+		m := net.IPMask(mask)
+		return ip.Mask(m).Equal(pattern.Mask(m))
+	}},
+}
+
+// parsePAC parses the PAC file, which must be in the supported form.
+func parsePAC(source string) (*pacScript, error) {
+	tokens, err := lexPAC(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &pacParser{tokens: tokens}
+	rules, err := p.script()
+	if err != nil {
+		return nil, err
+	}
+	return &pacScript{
+		rules:    rules,
+		resolver: net.LookupHost,
+	}, nil
+}
+
+// findProxy evaluates FindProxyForURL for u and returns the proxy to use,
+// or nil to connect directly. Only the first supported entry of the result
+// is used.
+func (s *pacScript) findProxy(u *url.URL) (*url.URL, error) {
+	// like browsers, don't reveal credentials, or the paths of https
+	// URLs, to the script
+	arg := *u
+	arg.User = nil
+	if arg.Scheme == "https" {
+		arg.Path, arg.RawPath, arg.RawQuery = "/", "", ""
+	}
+	arg.Fragment = ""
+	params := map[pacArgKind]string{
+		pacURLParam:  arg.String(),
+		pacHostParam: u.Hostname(),
+	}
+	for _, rule := range s.rules {
+		if rule.cond == nil || s.holds(rule.cond, params) {
+			return parsePACResult(rule.result)
+		}
+	}
+	return nil, ErrPACNoResult
+}
+
+// holds returns whether any of the conjunctions holds.
+func (s *pacScript) holds(cond [][]pacTest, params map[pacArgKind]string) bool {
+	for _, conj := range cond {
+		all := true
+		for _, test := range conj {
+			args := make([]string, len(test.args))
+			for i, arg := range test.args {
+				if arg.kind == pacLiteral {
+					args[i] = arg.value
+				} else {
+					args[i] = params[arg.kind]
+				}
+			}
+			if pacFunctions[test.name].fn(s, args) == test.negate {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePACResult parses a list of proxies returned by FindProxyForURL, such
+// as "PROXY proxy.example.com:8080; DIRECT", returning the first one that
+// is supported.
+func parsePACResult(result string) (*url.URL, error) {
+	for entry := range strings.SplitSeq(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		var scheme string
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid proxy %q", strings.TrimSpace(entry))
+		}
+		return url.Parse(scheme + "://" + fields[1])
+	}
+	return nil, fmt.Errorf("no supported proxy in %q", result)
+}
+
+// resolveIPv4 returns the IPv4 address of host, or nil if it has none.
+func (s *pacScript) resolveIPv4(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4()
+	}
+	addrs, err := s.resolver(host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr).To4(); ip != nil {
+			return ip
+		}
+	}
+	return nil
+}
+
+// shExpMatch matches str against a shell expression, in which * matches any
+// sequence of characters, including slashes, and ? matches any character.
+func shExpMatch(str, shexp string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, c := range shexp {
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(str)
+}
+
+// Lexer
+
+type pacTokenKind int
+
+const (
+	pacEOF pacTokenKind = iota
+	pacIdent
+	pacString
+	pacPunct
+)
+
+type pacToken struct {
+	kind pacTokenKind
+	text string
+	line int
+}
+
+// pacPuncts are the punctuators, longest first so that they're matched
+// greedily.
+var pacPuncts = []string{"||", "&&", "(", ")", "{", "}", ",", ";", "!"}
+
+func lexPAC(src string) ([]pacToken, error) {
+	var tokens []pacToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			// escapes aren't supported, since host names and proxies
+			// don't need them
+			end := strings.IndexAny(src[i+1:], string(c)+"\\\n")
+			if end < 0 || src[i+1+end] != c {
+				return nil, fmt.Errorf("line %d: unterminated or escaped string", line)
+			}
+			tokens = append(tokens, pacToken{pacString, src[i+1 : i+1+end], line})
+			i += end + 2
+		case isPACIdentChar(c) && (c < '0' || c > '9'):
+			j := i + 1
+			for j < len(src) && isPACIdentChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, pacToken{pacIdent, src[i:j], line})
+			i = j
+		default:
+			found := false
+			for _, punct := range pacPuncts {
+				if strings.HasPrefix(src[i:], punct) {
+					tokens = append(tokens, pacToken{pacPunct, punct, line})
+					i += len(punct)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("line %d: unsupported character %q", line, c)
+			}
+		}
+	}
+	return append(tokens, pacToken{pacEOF, "", line}), nil
+}
+
+func isPACIdentChar(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// Parser
+
+type pacParser struct {
+	tokens []pacToken
+	pos    int
+	// urlParam and hostParam are the names of the parameters of
+	// FindProxyForURL.
+	urlParam, hostParam string
+}
+
+func (p *pacParser) next() pacToken {
+	t := p.tokens[p.pos]
+	if t.kind != pacEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it's the given punctuator or
+// identifier.
+func (p *pacParser) accept(text string) bool {
+	if t := p.tokens[p.pos]; t.kind != pacString && t.kind != pacEOF && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *pacParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *pacParser) take(kind pacTokenKind, what string) (string, error) {
+	if p.tokens[p.pos].kind != kind {
+		return "", p.errorf("expected %s", what)
+	}
+	return p.next().text, nil
+}
+
+func (p *pacParser) errorf(format string, args ...any) error {
+	t := p.tokens[p.pos]
+	found := t.text
+	switch t.kind {
+	case pacEOF:
+		found = "end of file"
+	case pacString:
+		found = "string"
+	}
+	return fmt.Errorf("line %d: %s, found %q; see the operator notes for the supported form of proxy auto-config files", t.line, fmt.Sprintf(format, args...), found)
+}
+
+// script parses
+//
+//	function FindProxyForURL(url, host) { rules }
+func (p *pacParser) script() ([]pacRule, error) {
+	if err := p.expect("function"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("FindProxyForURL"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.urlParam, err = p.take(pacIdent, "parameter name"); err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	if p.hostParam, err = p.take(pacIdent, "parameter name"); err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var rules []pacRule
+	for !p.accept("}") {
+		rule, err := p.rule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+		if rule.cond == nil {
+			// nothing after an unconditional return is reached
+			if err := p.expect("}"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if p.tokens[p.pos].kind != pacEOF {
+		return nil, p.errorf("expected end of file")
+	}
+	return rules, nil
+}
+
+// rule parses
+//
+//	[else] [if (cond)] return "result";
+//
+// where the return may be in braces.
+func (p *pacParser) rule() (pacRule, error) {
+	// every rule returns, so else doesn't change the meaning
+	p.accept("else")
+	var rule pacRule
+	if p.accept("if") {
+		if err := p.expect("("); err != nil {
+			return rule, err
+		}
+		cond, err := p.cond()
+		if err != nil {
+			return rule, err
+		}
+		if err := p.expect(")"); err != nil {
+			return rule, err
+		}
+		rule.cond = cond
+	}
+	braced := p.accept("{")
+	if err := p.expect("return"); err != nil {
+		return rule, err
+	}
+	result, err := p.take(pacString, "string")
+	if err != nil {
+		return rule, err
+	}
+	rule.result = result
+	p.accept(";")
+	if braced {
+		if err := p.expect("}"); err != nil {
+			return rule, err
+		}
+	}
+	return rule, nil
+}
+
+// cond parses tests joined by || and &&, where && binds more tightly.
+func (p *pacParser) cond() ([][]pacTest, error) {
+	var cond [][]pacTest
+	for {
+		var conj []pacTest
+		for {
+			test, err := p.test()
+			if err != nil {
+				return nil, err
+			}
+			conj = append(conj, test)
+			if !p.accept("&&") {
+				break
+			}
+		}
+		cond = append(cond, conj)
+		if !p.accept("||") {
+			return cond, nil
+		}
+	}
+}
+
+// test parses a possibly negated call of a PAC helper function.
+func (p *pacParser) test() (pacTest, error) {
+	test := pacTest{negate: p.accept("!")}
+	if p.tokens[p.pos].kind != pacIdent {
+		return test, p.errorf("expected function call")
+	}
+	test.name = p.tokens[p.pos].text
+	fn, ok := pacFunctions[test.name]
+	if !ok {
+		return test, p.errorf("unsupported function")
+	}
+	p.next()
+	if err := p.expect("("); err != nil {
+		return test, err
+	}
+	for i := 0; i < fn.args; i++ {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return test, err
+			}
+		}
+		arg, err := p.arg()
+		if err != nil {
+			return test, err
+		}
+		test.args = append(test.args, arg)
+	}
+	if err := p.expect(")"); err != nil {
+		return test, err
+	}
+	return test, nil
+}
+
+// arg parses a string literal or a parameter of FindProxyForURL.
+func (p *pacParser) arg() (pacArg, error) {
+	t := p.tokens[p.pos]
+	switch {
+	case t.kind == pacString:
+		p.next()
+		return pacArg{kind: pacLiteral, value: t.text}, nil
+	case t.kind == pacIdent && t.text == p.urlParam:
+		p.next()
+		return pacArg{kind: pacURLParam}, nil
+	case t.kind == pacIdent && t.text == p.hostParam:
+		p.next()
+		return pacArg{kind: pacHostParam}, nil
+	default:
+		return pacArg{}, p.errorf("expected string or %q or %q", p.urlParam, p.hostParam)
+	}
+}
@@ -0,0 +1,525 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// peerBlobPath is the path under which peers serve cached resources,
+	// named by their cache key.
+	peerBlobPath = "/ignition/v1/blobs/"
+
+	// peerProbe is broadcast to discover peers, which reply with
+	// peerReplyPrefix followed by the port on which they serve.
+	peerProbe       = "ignition-peer-discover"
+	peerReplyPrefix = "ignition-peer "
+
+	// peerDiscoveryTimeout is how long to wait for replies to a probe.
+	peerDiscoveryTimeout = 500 * time.Millisecond
+	// peerDiscoveryInterval is how long discovered peers are remembered
+	// before probing again, since peers come and go as they're provisioned.
+	peerDiscoveryInterval = 30 * time.Second
+	// peerFetchTimeout bounds a download from one peer, so that a slow
+	// peer can't stall the fetch; the resource is then fetched from the
+	// next peer or its source.
+	peerFetchTimeout = 5 * time.Minute
+)
+
+var ErrPeerSharingNeedsCache = errors.New("sharing resources with peers requires the resource cache")
+
+// peerSession holds the peers from which to fetch resources, and the
+// resources which no peer had.
+type peerSession struct {
+	config types.Peers
+	// addresses are the configured peers, in host:port form.
+	addresses []string
+	// discoveryAddr is where to send discovery probes, or "" if peers
+	// aren't discovered.
+	discoveryAddr string
+	client        *http.Client
+
+	mu           sync.Mutex
+	discovered   []string
+	discoveredAt time.Time
+	misses       map[string]bool
+}
+
+func newPeerSession(peers types.Peers) (*peerSession, error) {
+	port := peerPort(peers)
+	s := &peerSession{
+		config: peers,
+		client: &http.Client{
+			Timeout: peerFetchTimeout,
+			// peers are on the local network, so never use a proxy
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout: time.Second,
+				}).DialContext,
+				ResponseHeaderTimeout: 5 * time.Second,
+			},
+		},
+		misses: make(map[string]bool),
+	}
+	for _, address := range peers.Addresses {
+		a, err := cutil.ParsePeerAddress(address, port)
+		if err != nil {
+			return nil, err
+		}
+		s.addresses = append(s.addresses, a)
+	}
+	if cutil.IsTrue(peers.Discover) {
+		s.discoveryAddr = net.JoinHostPort(net.IPv4bcast.String(), strconv.Itoa(port))
+	}
+	return s, nil
+}
+
+func peerPort(peers types.Peers) int {
+	if peers.Port != nil {
+		return *peers.Port
+	}
+	return cutil.DefaultPeerPort
+}
+
+// updatePeers sets the peers from which to fetch resources. Discovered
+// peers and misses are kept if the config is unchanged.
+func (f *Fetcher) updatePeers(peers types.Peers) error {
+	if len(peers.Addresses) == 0 && !cutil.IsTrue(peers.Discover) {
+		f.client.peers = nil
+		return nil
+	}
+	if f.client.peers != nil && reflect.DeepEqual(f.client.peers.config, peers) {
+		return nil
+	}
+	session, err := newPeerSession(peers)
+	if err != nil {
+		return err
+	}
+	f.client.peers = session
+	return nil
+}
+
+// peerKey returns the key under which peers share the resource, or "" if
+// it can't be fetched from peers. Like the cache, only remote resources
+// with a verification hash are shared, since the hash lets us check what
+// a peer sends.
+func (f *Fetcher) peerKey(u url.URL, opts FetchOptions) string {
+	if f.client == nil || f.client.peers == nil || f.Offline {
+		return ""
+	}
+	return contentKey(u, opts)
+}
+
+// peerShares are the cache entries which may be served to peers.
+type peerShares struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (s *peerShares) add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key] = true
+}
+
+func (s *peerShares) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[key]
+}
+
+// shareable returns whether the resource may be served to peers. Peers
+// don't authenticate, so only resources fetched without credentials, from
+// schemes which don't hold secrets, and which aren't marked sensitive are
+// shared.
+func (f *Fetcher) shareable(u url.URL, opts FetchOptions) bool {
+	if opts.Sensitive || len(opts.Headers) > 0 || opts.Auth.IsPresent() || u.User != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https":
+		// Azure blobs are fetched with the machine's credentials
+		return f.AzSession == nil || !strings.HasSuffix(u.Host, ".blob.core.windows.net")
+	case "tftp":
+		return true
+	default:
+		// s3, gs, and oci resources may be fetched with the machine's
+		// credentials, and vault and registered schemes may hold secrets
+		return false
+	}
+}
+
+// shareCacheEntry allows the cache entry with the given key, which holds
+// the resource at u, to be served to peers if the resource is shareable.
+func (f *Fetcher) shareCacheEntry(key string, u url.URL, opts FetchOptions) {
+	if f.client != nil && f.shareable(u, opts) {
+		f.client.shares.add(key)
+	}
+}
+
+// fetchWithPeers fetches the resource from a peer if one has it, and
+// otherwise from u.
+func (f *Fetcher) fetchWithPeers(u url.URL, dest *os.File, opts FetchOptions) error {
+	if key := f.peerKey(u, opts); key != "" {
+		if f.fetchFromPeers(key, dest, func() { f.resetFile(dest) }, opts) {
+			return nil
+		}
+	}
+	return f.fetch(u, dest, opts)
+}
+
+// fetchToBufferWithPeers is the fetchToBuffer counterpart of
+// fetchWithPeers.
+func (f *Fetcher) fetchToBufferWithPeers(u url.URL, opts FetchOptions) ([]byte, error) {
+	if key := f.peerKey(u, opts); key != "" {
+		dest := new(bytes.Buffer)
+		if f.fetchFromPeers(key, dest, dest.Reset, opts) {
+			return dest.Bytes(), nil
+		}
+	}
+	return f.fetchToBuffer(u, opts)
+}
+
+// fetchFromPeers tries to fetch the resource with the given key from each
+// peer in turn, writing it into dest, and returns whether one had it. dest
+// is reset after each failed attempt. Failures are logged rather than
+// returned, since the resource can still be fetched from its source.
+func (f *Fetcher) fetchFromPeers(key string, dest io.Writer, reset func(), opts FetchOptions) bool {
+	session := f.client.peers
+	session.mu.Lock()
+	missed := session.misses[key]
+	session.mu.Unlock()
+	if missed {
+		return false
+	}
+	for _, peer := range session.peers(f) {
+		err := f.fetchFromPeer(session.client, peer, key, dest, opts)
+		if err == nil {
+			f.Logger.Info("fetched resource %s from peer %s", key, peer)
+			return true
+		}
+		reset()
+		if err != ErrNotFound {
+			f.Logger.Debug("failed to fetch resource %s from peer %s: %v", key, peer, err)
+		}
+	}
+	session.mu.Lock()
+	session.misses[key] = true
+	session.mu.Unlock()
+	return false
+}
+
+func (f *Fetcher) fetchFromPeer(client *http.Client, peer, key string, dest io.Writer, opts FetchOptions) error {
+	limits := f.limits()
+	defer limits.acquire()()
+	resp, err := client.Get("http://" + peer + peerBlobPath + key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// peers serve the resource as it was cached, after decompression
+	hashers := opts.contentHashers()
+	if _, err := io.Copy(io.MultiWriter(append([]io.Writer{dest}, hashers...)...), limits.reader(resp.Body)); err != nil {
+		return err
+	}
+	return f.verifySum(opts)
+}
+
+// peers returns the configured peers followed by the discovered ones, in
+// random order so that load is spread across them.
+func (s *peerSession) peers(f *Fetcher) []string {
+	if s.discoveryAddr == "" {
+		return s.addresses
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.discoveredAt) > peerDiscoveryInterval {
+		discovered, err := discoverPeers(s.discoveryAddr)
+		if err != nil {
+			f.Logger.Warning("failed to discover peers: %v", err)
+		}
+		rand.Shuffle(len(discovered), func(i, j int) {
+			discovered[i], discovered[j] = discovered[j], discovered[i]
+		})
+		s.discovered = discovered
+		s.discoveredAt = time.Now()
+		// peers which didn't have a resource before might now
+		clear(s.misses)
+	}
+	return append(append([]string{}, s.addresses...), s.discovered...)
+}
+
+// discoverPeers sends a probe to address and returns the addresses of the
+// peers which reply.
+func discoverPeers(address string) ([]string, error) {
+	target, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, err
+	}
+	lc := net.ListenConfig{
+		Control: socketControl(func(fd int) error {
+			return unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
+		}),
+	}
+	conn, err := lc.ListenPacket(context.Background(), "udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if _, err := conn.WriteTo([]byte(peerProbe), target); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(peerDiscoveryTimeout)); err != nil {
+		return nil, err
+	}
+	var peers []string
+	seen := make(map[string]bool)
+	buf := make([]byte, 64)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return peers, nil
+		} else if err != nil {
+			return peers, err
+		}
+		port, ok := strings.CutPrefix(string(buf[:n]), peerReplyPrefix)
+		if !ok {
+			continue
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			continue
+		}
+		peer := net.JoinHostPort(from.(*net.UDPAddr).IP.String(), port)
+		if !seen[peer] {
+			seen[peer] = true
+			peers = append(peers, peer)
+		}
+	}
+}
+
+// ServePeers shares resources in the cache with peers until the returned
+// function is called. Only entries which this process fetched or used and
+// which are shareable are served. Resources are served over HTTP on the
+// configured port of the interface through which peers are reached, which
+// also answers discovery probes, so peers only need to know one port.
+func (f *Fetcher) ServePeers(peers types.Peers) (func(), error) {
+	if f.CacheDir == "" {
+		return nil, ErrPeerSharingNeedsCache
+	}
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return nil, err
+		}
+	}
+	port := peerPort(peers)
+	iface, ip, err := peerInterface(peers, port)
+	if err != nil {
+		return nil, fmt.Errorf("finding the interface to serve peers on: %w", err)
+	}
+	address := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	// broadcast probes aren't delivered to sockets bound to a unicast
+	// address, so bind to the interface instead
+	lc := net.ListenConfig{
+		Control: socketControl(func(fd int) error {
+			return unix.SetsockoptString(fd, unix.SOL_SOCKET, unix.SO_BINDTODEVICE, iface.Name)
+		}),
+	}
+	conn, err := lc.ListenPacket(context.Background(), "udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	f.Logger.Info("sharing cached resources with peers on %s (%s)", address, iface.Name)
+	return f.servePeers(listener, conn), nil
+}
+
+// peerInterface returns the network interface through which peers are
+// reached, and its address: the interface routing to the first configured
+// peer, or if there are none, the one routing discovery broadcasts.
+func peerInterface(peers types.Peers, port int) (*net.Interface, net.IP, error) {
+	target := net.JoinHostPort(net.IPv4bcast.String(), strconv.Itoa(port))
+	if len(peers.Addresses) > 0 {
+		var err error
+		target, err = cutil.ParsePeerAddress(peers.Addresses[0], port)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// connecting a UDP socket only chooses the route; nothing is sent
+	d := net.Dialer{
+		Control: socketControl(func(fd int) error {
+			return unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
+		}),
+	}
+	conn, err := d.Dial("udp", target)
+	if err != nil {
+		return nil, nil, err
+	}
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	_ = conn.Close()
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(local) {
+				return &iface, local, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("no interface has address %s", local)
+}
+
+// socketControl returns a Control function for net.Dialer or
+// net.ListenConfig which applies set to the socket.
+func socketControl(set func(fd int) error) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var serr error
+		err := c.Control(func(fd uintptr) {
+			serr = set(int(fd))
+		})
+		if err != nil {
+			return err
+		}
+		return serr
+	}
+}
+
+// servePeers serves cached resources on listener and answers discovery
+// probes on conn, until the returned function is called.
+func (f *Fetcher) servePeers(listener net.Listener, conn net.PacketConn) func() {
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	server := &http.Server{
+		Handler:           http.HandlerFunc(f.servePeerBlob),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = server.Serve(listener)
+	}()
+	go func() {
+		defer wg.Done()
+		answerPeerProbes(conn, port)
+	}()
+	return func() {
+		_ = server.Close()
+		_ = conn.Close()
+		wg.Wait()
+	}
+}
+
+// servePeerBlob serves the cache entry named by the request path, if it's
+// shareable.
+func (f *Fetcher) servePeerBlob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, peerBlobPath)
+	if !ok || !isCacheKey(key) || !f.client.shares.has(key) {
+		http.NotFound(w, r)
+		return
+	}
+	entry, err := os.Open(filepath.Join(f.CacheDir, key))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() {
+		_ = entry.Close()
+	}()
+	st, err := entry.Stat()
+	if err != nil || !st.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	f.Logger.Debug("sharing resource %s with peer %s", key, r.RemoteAddr)
+	http.ServeContent(w, r, "", st.ModTime(), entry)
+}
+
+// isCacheKey returns whether key could name a cache entry, so that
+// requests can't reach other files.
+func isCacheKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') && c != '-' && c != '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// answerPeerProbes replies to discovery probes received on conn until it's
+// closed.
+func answerPeerProbes(conn net.PacketConn, port string) {
+	buf := make([]byte, 64)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+		if string(buf[:n]) == peerProbe {
+			_, _ = conn.WriteTo([]byte(peerReplyPrefix+port), from)
+		}
+	}
+}
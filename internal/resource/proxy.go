@@ -0,0 +1,286 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/util"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// wpadTimeout bounds each request for a discovered PAC file.
+	wpadTimeout = 5 * time.Second
+	// maxPACSize bounds the size of a discovered PAC file.
+	maxPACSize = 1 << 20
+	// resolvConfPath lists the search domains in which to look for a
+	// WPAD server.
+	resolvConfPath = "/etc/resolv.conf"
+)
+
+// proxySelector chooses the proxy for each request from ignition.proxy. It's
+// used by every client which goes through the HttpClient's transport,
+// including the S3, GCS, and Azure Blob clients.
+type proxySelector struct {
+	config types.Proxy
+	logger *log.Logger
+
+	// static applies httpProxy, httpsProxy, and noProxy.
+	static func(*url.URL) (*url.URL, error)
+	// excluded returns whether noProxy, or the implicit exclusion of
+	// loopback addresses, applies to the URL.
+	excluded func(*url.URL) bool
+	// pac, if set, chooses the proxy for URLs which aren't excluded.
+	pac *pacScript
+	// credentials, if set, authenticate to proxies which don't have
+	// credentials in their URL.
+	credentials *url.Userinfo
+	// loaded is set once the credentials and PAC file are loaded.
+	loaded bool
+}
+
+// newProxySelector returns a selector which applies the static settings of
+// the config, until loadProxyResources loads the rest.
+func newProxySelector(proxy types.Proxy, logger *log.Logger) *proxySelector {
+	probe := (&httpproxy.Config{
+		HTTPProxy:  "proxy.invalid",
+		HTTPSProxy: "proxy.invalid",
+		NoProxy:    translateNoProxySliceToString(proxy.NoProxy),
+	}).ProxyFunc()
+	return &proxySelector{
+		config: proxy,
+		logger: logger,
+		static: proxyFuncFromIgnitionConfig(proxy),
+		excluded: func(u *url.URL) bool {
+			p, err := probe(u)
+			return err == nil && p == nil
+		},
+	}
+}
+
+// matches returns whether the selector has loaded the config, so the
+// credentials and PAC file needn't be fetched again.
+func (p *proxySelector) matches(proxy types.Proxy) bool {
+	return p != nil && p.loaded && reflect.DeepEqual(p.config, proxy)
+}
+
+func (f *Fetcher) setProxy(selector *proxySelector) {
+	f.client.proxy = selector
+	f.client.transport.Proxy = selector.proxy
+	f.client.client.Transport = f.client.transport
+}
+
+// loadProxyResources fetches the proxy credentials and PAC file of the
+// selector, through its static settings, and starts using them.
+func (f *Fetcher) loadProxyResources(selector *proxySelector) error {
+	proxy := selector.config
+	var credentials *url.Userinfo
+	if cutil.NotEmpty(proxy.Credentials.Source) {
+		data, err := f.fetchProxyResource(proxy.Credentials)
+		if err != nil {
+			return fmt.Errorf("fetching proxy credentials: %w", err)
+		}
+		value := strings.TrimSpace(string(data))
+		f.Logger.AddSecrets(value)
+		if username, password, ok := strings.Cut(value, ":"); ok {
+			f.Logger.AddSecrets(password)
+			credentials = url.UserPassword(username, password)
+		} else {
+			credentials = url.User(value)
+		}
+	}
+
+	var pac *pacScript
+	if cutil.NotEmpty(proxy.AutoConfig.Source) {
+		data, err := f.fetchProxyResource(proxy.AutoConfig)
+		if err != nil {
+			return fmt.Errorf("fetching proxy auto-config file: %w", err)
+		}
+		if pac, err = parsePAC(string(data)); err != nil {
+			return fmt.Errorf("parsing proxy auto-config file: %w", err)
+		}
+	} else if cutil.IsTrue(proxy.AutoDiscover) {
+		if f.Offline {
+			return ErrNeedNet
+		}
+		pac = f.discoverPAC(wpadURLs(searchDomains()))
+	}
+
+	loaded := *selector
+	loaded.credentials = credentials
+	loaded.pac = pac
+	loaded.loaded = true
+	f.setProxy(&loaded)
+	return nil
+}
+
+// fetchProxyResource fetches and decrypts the proxy auto-config file or
+// credentials, keeping the fetched resource for RewriteProxyWithDataUrls.
+func (f *Fetcher) fetchProxyResource(res types.Resource) ([]byte, error) {
+	blob, err := f.getTLSBlob(res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Encryption.IsPresent() {
+		blob, err = util.Decrypt(res.Encryption, blob)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting resource: %v", err)
+		}
+	}
+	return blob, nil
+}
+
+// RewriteProxyWithDataUrls is like RewriteCAsWithDataUrls for the proxy
+// auto-config file and credentials, so later stages don't fetch them again.
+// The credentials are left encrypted, if they were.
+func (f *Fetcher) RewriteProxyWithDataUrls(proxy *types.Proxy) error {
+	for _, res := range []*types.Resource{&proxy.AutoConfig, &proxy.Credentials} {
+		if cutil.NilOrEmpty(res.Source) {
+			continue
+		}
+		if err := f.rewriteWithDataUrl(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxy returns the proxy to use for the request, or nil to connect
+// directly.
+func (p *proxySelector) proxy(req *http.Request) (*url.URL, error) {
+	proxy, fromPAC, err := p.find(req.URL)
+	if err != nil || proxy == nil || p.credentials == nil || proxy.User != nil {
+		return proxy, err
+	}
+	if fromPAC && proxy.Scheme != "https" {
+		// the PAC file may have been discovered on the network, so
+		// only send the credentials to proxies it chooses if the
+		// connection is encrypted
+		return proxy, nil
+	}
+	// the transport sends the credentials in Proxy-Authorization
+	withCredentials := *proxy
+	withCredentials.User = p.credentials
+	return &withCredentials, nil
+}
+
+// find returns the proxy to use for u, and whether the PAC file chose it.
+func (p *proxySelector) find(u *url.URL) (*url.URL, bool, error) {
+	if p.pac == nil || p.excluded(u) {
+		proxy, err := p.static(u)
+		return proxy, false, err
+	}
+	proxy, err := p.pac.findProxy(u)
+	if err != nil {
+		p.logger.Warning("proxy auto-config failed for %s: %v; falling back to httpProxy and httpsProxy", RedactedURL(*u), err)
+		proxy, err := p.static(u)
+		return proxy, false, err
+	}
+	return proxy, true, nil
+}
+
+// discoverPAC fetches the first PAC file available from the candidate
+// WPAD URLs, returning nil if there's none. The PAC file is always fetched
+// directly, since it says which proxy to use.
+func (f *Fetcher) discoverPAC(candidates []string) *pacScript {
+	client := &http.Client{
+		Transport: &http.Transport{},
+		Timeout:   wpadTimeout,
+	}
+	for _, candidate := range candidates {
+		script, err := fetchPAC(client, candidate)
+		if err != nil {
+			f.Logger.Debug("no proxy auto-config file at %s: %v", candidate, err)
+			continue
+		}
+		f.Logger.Info("using proxy auto-config file from %s", candidate)
+		return script
+	}
+	f.Logger.Warning("couldn't discover a proxy auto-config file; falling back to httpProxy and httpsProxy")
+	return nil
+}
+
+func fetchPAC(client *http.Client, u string) (*pacScript, error) {
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPACSize))
+	if err != nil {
+		return nil, err
+	}
+	return parsePAC(string(data))
+}
+
+// wpadURLs returns the URLs at which to look for a PAC file, following the
+// DNS variant of WPAD: wpad is prepended to each search domain. Unlike
+// browsers, parent domains aren't tried, since a parent such as co.uk may
+// belong to someone else, who would then choose our proxy. Single-label
+// domains are skipped for the same reason.
+func wpadURLs(domains []string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, domain := range domains {
+		domain = strings.Trim(domain, ".")
+		if !strings.Contains(domain, ".") {
+			continue
+		}
+		u := "http://wpad." + domain + "/wpad.dat"
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// searchDomains returns the DNS search domains of the system.
+func searchDomains() []string {
+	file, err := os.Open(resolvConfPath)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	var domains []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && (fields[0] == "search" || fields[0] == "domain") {
+			domains = append(domains, fields[1:]...)
+		}
+	}
+	return domains
+}
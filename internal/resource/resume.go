@@ -0,0 +1,150 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// resumableReader reads the body of an HTTP response, resuming the
+// download with a Range request if the connection fails partway through.
+// Readers see a single uninterrupted stream, so decompression and hashing
+// carry on across resumed requests. The resumed requests share the context
+// of the original one, so the total timeout bounds the whole download.
+type resumableReader struct {
+	client HttpClient
+	ctx    context.Context
+	opts   FetchOptions
+	url    string
+
+	body io.ReadCloser
+
+	// validator is the ETag or Last-Modified of the original response,
+	// sent in If-Range so that a resource which has changed isn't
+	// spliced onto the bytes already read.
+	validator string
+	// offset is the number of bytes read so far.
+	offset int64
+	// failures is the number of consecutive resume attempts which
+	// haven't made progress.
+	failures int
+}
+
+// resumable wraps the body of resp, which was requested in ctx, so that the
+// download is resumed if it fails, when the server supports it.
+func (c HttpClient) resumable(ctx context.Context, opts FetchOptions, url string, resp *http.Response) io.ReadCloser {
+	validator := resumeValidator(opts, resp)
+	if validator == "" {
+		return resp.Body
+	}
+	return &resumableReader{
+		client:    c,
+		ctx:       ctx,
+		opts:      opts,
+		url:       url,
+		body:      resp.Body,
+		validator: validator,
+	}
+}
+
+// resumeValidator returns the validator to resume the response with, or ""
+// if it can't be resumed.
+func resumeValidator(opts FetchOptions, resp *http.Response) string {
+	if opts.HTTPVerb != "" && opts.HTTPVerb != http.MethodGet {
+		return ""
+	}
+	if resp.StatusCode != http.StatusOK || resp.Uncompressed {
+		return ""
+	}
+	if !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Accept-Ranges")), "bytes") {
+		return ""
+	}
+	// weak ETags can't be used with If-Range
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		if resumeErr := r.resume(err); resumeErr != nil {
+			return n, resumeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume replaces the failed body with the rest of the resource, following
+// the retry policy. It returns an error if the download can't be resumed,
+// including when the total timeout has expired.
+func (r *resumableReader) resume(cause error) error {
+	_ = r.body.Close()
+	r.body = http.NoBody
+	for {
+		if r.ctx.Err() != nil {
+			return ErrTimeout
+		}
+		r.failures++
+		duration, ok := r.client.retry.backoff(r.failures)
+		if !ok {
+			return cause
+		}
+		r.client.logger.Info("%s: resuming at byte %d after error: %v", r.url, r.offset, cause)
+		select {
+		case <-time.After(duration):
+		case <-r.ctx.Done():
+			return ErrTimeout
+		}
+
+		opts := r.opts
+		opts.Headers = make(http.Header)
+		for k, va := range r.opts.Headers {
+			opts.Headers[k] = va
+		}
+		opts.Headers.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		opts.Headers.Set("If-Range", r.validator)
+		resp, err := r.client.httpResponseWithContext(r.ctx, opts, r.url)
+		if err != nil {
+			cause = err
+			continue
+		}
+		if resp.StatusCode != http.StatusPartialContent || !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", r.offset)) {
+			_ = resp.Body.Close()
+			return fmt.Errorf("couldn't resume download (%s): %w", http.StatusText(resp.StatusCode), cause)
+		}
+		r.body = resp.Body
+		return nil
+	}
+}
+
+func (r *resumableReader) Close() error {
+	return r.body.Close()
+}
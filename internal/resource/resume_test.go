@@ -0,0 +1,175 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	sum := sha512.Sum512(data)
+
+	tests := []struct {
+		name string
+		// drops is the number of responses cut off partway through
+		drops int
+		// changed is whether the resource changes after the first
+		// response
+		changed bool
+		etag    string
+		err     bool
+	}{
+		{
+			name: "uninterrupted",
+			etag: `"v1"`,
+		},
+		{
+			name:  "resumed",
+			drops: 3,
+			etag:  `"v1"`,
+		},
+		{
+			name:    "changed",
+			drops:   1,
+			changed: true,
+			etag:    `"v1"`,
+			err:     true,
+		},
+		{
+			name:  "no validator",
+			drops: 1,
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if test.etag != "" {
+					etag := test.etag
+					if test.changed && n > 1 {
+						etag = `"v2"`
+					}
+					w.Header().Set("ETag", etag)
+				}
+				if int(n) <= test.drops {
+					// send part of the remaining data, then drop
+					// the connection
+					start := int64(0)
+					if r.Header.Get("Range") != "" {
+						rw := httptest.NewRecorder()
+						rw.Header().Set("ETag", w.Header().Get("ETag"))
+						http.ServeContent(rw, r, "", time.Time{}, bytes.NewReader(data))
+						if rw.Code != http.StatusPartialContent {
+							w.WriteHeader(rw.Code)
+							return
+						}
+						start = int64(len(data)) - int64(rw.Body.Len())
+						w.Header().Set("Content-Range", rw.Header().Get("Content-Range"))
+						w.Header().Set("Accept-Ranges", "bytes")
+						w.Header().Set("Content-Length", rw.Header().Get("Content-Length"))
+						w.WriteHeader(http.StatusPartialContent)
+					} else {
+						w.Header().Set("Accept-Ranges", "bytes")
+						w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+						w.WriteHeader(http.StatusOK)
+					}
+					_, _ = w.Write(data[start : start+int64(len(data))/8])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+			}))
+			defer server.Close()
+			u, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			logger := log.New(true)
+			f := Fetcher{Logger: &logger}
+			retry := types.Retry{MaxAttempts: util.IntToPtr(5), InitialBackoff: util.IntToPtr(1)}
+			require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, types.TLS{}, types.Proxy{}))
+			got, err := f.FetchToBuffer(*u, FetchOptions{
+				Hash:        sha512.New(),
+				ExpectedSum: sum[:],
+			})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+			assert.Equal(t, int32(test.drops+1), atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestResumeTimeout(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// send a little more of the data each time, then drop the
+		// connection, so that every resumed request makes progress
+		var start int
+		if rng := r.Header.Get("Range"); rng != "" {
+			var err error
+			start, err = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)-start))
+		if start > 0 {
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write(data[start : start+1024])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	retry := types.Retry{InitialBackoff: util.IntToPtr(10)}
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{HTTPTotal: util.IntToPtr(1)}, retry, types.TLS{}, types.Proxy{}))
+	started := time.Now()
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+const (
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// retryPolicy controls how failed fetches are retried.
+type retryPolicy struct {
+	// configured is whether the policy came from ignition.retry. Schemes
+	// other than http(s) are only retried by Ignition, rather than by
+	// their client library, if it did.
+	configured bool
+	// maxAttempts is the maximum number of attempts, or 0 for no limit.
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// statusCodes are retried in addition to 5xx status codes.
+	statusCodes []int
+	tlsErrors   bool
+}
+
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		tlsErrors:      true,
+	}
+}
+
+func newRetryPolicy(r types.Retry) retryPolicy {
+	p := defaultRetryPolicy()
+	if r.MaxAttempts != nil {
+		p.configured = true
+		p.maxAttempts = *r.MaxAttempts
+	}
+	if r.InitialBackoff != nil {
+		p.configured = true
+		p.initialBackoff = time.Duration(*r.InitialBackoff) * time.Millisecond
+		if p.initialBackoff > p.maxBackoff {
+			p.maxBackoff = p.initialBackoff
+		}
+	}
+	if r.MaxBackoff != nil {
+		p.configured = true
+		p.maxBackoff = time.Duration(*r.MaxBackoff) * time.Millisecond
+		if p.initialBackoff > p.maxBackoff {
+			p.initialBackoff = p.maxBackoff
+		}
+	}
+	if len(r.StatusCodes) > 0 {
+		p.configured = true
+		p.statusCodes = r.StatusCodes
+	}
+	if r.TLSErrors != nil {
+		p.configured = true
+		p.tlsErrors = *r.TLSErrors
+	}
+	return p
+}
+
+// retryPolicy returns the retry policy of the fetcher's client.
+func (f *Fetcher) retryPolicy() retryPolicy {
+	if f.client == nil {
+		return defaultRetryPolicy()
+	}
+	return f.client.retry
+}
+
+// delay returns how long to wait after the given failed attempt.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.maxBackoff)
+}
+
+// backoff returns how long to wait after the given failed attempt, or false
+// if there are no attempts remaining.
+func (p retryPolicy) backoff(attempt int) (time.Duration, bool) {
+	if p.maxAttempts > 0 && attempt >= p.maxAttempts {
+		return 0, false
+	}
+	return p.delay(attempt), true
+}
+
+// retryStatus returns whether a response with the status code should be
+// retried.
+func (p retryPolicy) retryStatus(statusCode int, opts FetchOptions) bool {
+	// we always retry 500+
+	if statusCode >= 500 {
+		return true
+	}
+
+	return slices.Contains(opts.RetryCodes, statusCode) || slices.Contains(p.statusCodes, statusCode)
+}
+
+// retryError returns whether a request which failed without a response
+// should be retried.
+func (p retryPolicy) retryError(err error) bool {
+	return p.tlsErrors || !isTLSError(err)
+}
+
+// retryNetError returns whether a fetch which failed with err should be
+// retried, for schemes where only network errors are transient.
+func (p retryPolicy) retryNetError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && p.retryError(err)
+}
+
+// do calls fetch until it succeeds, returns an error for which retryable is
+// false, or runs out of attempts. ErrTimeout is returned if ctx expires
+// while waiting to retry.
+func (p retryPolicy) do(ctx context.Context, f *Fetcher, desc string, retryable func(error) bool, fetch func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fetch()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		duration, ok := p.backoff(attempt)
+		if !ok {
+			return err
+		}
+		f.Logger.Info("%s: attempt #%d failed: %v", desc, attempt, err)
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+			return ErrTimeout
+		}
+	}
+}
+
+// awsRetryer returns an AWS SDK retryer which follows the policy.
+func (p retryPolicy) awsRetryer() aws.Retryer {
+	r := retry.NewStandard(func(o *retry.StandardOptions) {
+		o.Backoff = retry.BackoffDelayerFunc(func(attempt int, _ error) (time.Duration, error) {
+			return p.delay(attempt), nil
+		})
+		o.MaxBackoff = p.maxBackoff
+		// the attempt limit is ours to enforce
+		o.RateLimiter = ratelimit.None
+		var retryables []retry.IsErrorRetryable
+		if !p.tlsErrors {
+			retryables = append(retryables, retry.IsErrorRetryableFunc(func(err error) aws.Ternary {
+				if isTLSError(err) {
+					return aws.FalseTernary
+				}
+				return aws.UnknownTernary
+			}))
+		}
+		if len(p.statusCodes) > 0 {
+			codes := map[int]struct{}{}
+			for _, code := range p.statusCodes {
+				codes[code] = struct{}{}
+			}
+			retryables = append(retryables, retry.RetryableHTTPStatusCode{Codes: codes})
+		}
+		o.Retryables = append(retryables, o.Retryables...)
+	})
+	return retry.AddWithMaxAttempts(r, p.maxAttempts)
+}
+
+// azureRetryOptions returns Azure SDK retry options which follow the policy.
+func (p retryPolicy) azureRetryOptions() policy.RetryOptions {
+	opts := policy.RetryOptions{
+		MaxRetries:    math.MaxInt32,
+		RetryDelay:    p.initialBackoff,
+		MaxRetryDelay: p.maxBackoff,
+		ShouldRetry: func(resp *http.Response, err error) bool {
+			if err != nil {
+				return p.retryError(err)
+			}
+			return p.retryStatus(resp.StatusCode, FetchOptions{})
+		},
+	}
+	if p.maxAttempts > 0 {
+		opts.MaxRetries = int32(p.maxAttempts - 1)
+		if opts.MaxRetries == 0 {
+			// zero selects the default
+			opts.MaxRetries = -1
+		}
+	}
+	// zero selects the default; negative means no delay
+	if opts.RetryDelay == 0 {
+		opts.RetryDelay = -1
+	}
+	return opts
+}
+
+// isTLSError returns whether err was caused by a failed TLS handshake or
+// certificate verification.
+func isTLSError(err error) bool {
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordErr       tls.RecordHeaderError
+		alertErr        tls.AlertError
+		authorityErr    x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		invalidErr      x509.CertificateInvalidError
+	)
+	return errors.As(err, &verificationErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.Is(err, ErrPublicKeyNotPinned)
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := defaultRetryPolicy()
+	assert.False(t, p.configured)
+	for attempt, expected := range []time.Duration{200, 400, 800, 1600, 3200, 5000, 5000} {
+		d, ok := p.backoff(attempt + 1)
+		assert.True(t, ok)
+		assert.Equal(t, expected*time.Millisecond, d, "attempt %d", attempt+1)
+	}
+
+	p = newRetryPolicy(types.Retry{
+		MaxAttempts:    util.IntToPtr(3),
+		InitialBackoff: util.IntToPtr(1000),
+	})
+	assert.True(t, p.configured)
+	d, ok := p.backoff(1)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, d)
+	d, ok = p.backoff(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+	_, ok = p.backoff(3)
+	assert.False(t, ok)
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	p := newRetryPolicy(types.Retry{
+		StatusCodes: []int{429},
+		TLSErrors:   util.BoolToPtr(false),
+	})
+	assert.True(t, p.retryStatus(http.StatusServiceUnavailable, FetchOptions{}))
+	assert.True(t, p.retryStatus(http.StatusTooManyRequests, FetchOptions{}))
+	assert.True(t, p.retryStatus(http.StatusNotFound, FetchOptions{RetryCodes: []int{http.StatusNotFound}}))
+	assert.False(t, p.retryStatus(http.StatusNotFound, FetchOptions{}))
+
+	tlsErr := &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}
+	assert.False(t, p.retryError(tlsErr))
+	assert.True(t, p.retryError(fmt.Errorf("connection refused")))
+	assert.True(t, defaultRetryPolicy().retryError(tlsErr))
+}
+
+func TestFetchRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	retry := types.Retry{
+		MaxAttempts:    util.IntToPtr(2),
+		InitialBackoff: util.IntToPtr(0),
+		StatusCodes:    []int{http.StatusTooManyRequests},
+	}
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, types.TLS{}, types.Proxy{}))
+
+	// out of attempts
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.Equal(t, ErrFailed, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	requests.Store(0)
+	retry.MaxAttempts = util.IntToPtr(3)
+	require.NoError(t, f.UpdateHttpTimeoutsAndCAs(types.Timeouts{}, retry, types.TLS{}, types.Proxy{}))
+	data, err := f.FetchToBuffer(*u, FetchOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+	assert.Equal(t, int32(3), requests.Load())
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io"
+	"net/url"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/internal/registry"
+	"github.com/coreos/ignition/v2/internal/util"
+)
+
+// Scheme handles a source scheme. The schemes built into Ignition are
+// registered by this package; others, such as one added by a distribution,
+// are registered with RegisterScheme from an init function, and the package
+// imported from internal/register.
+type Scheme interface {
+	// Name returns the URL scheme, such as "vault".
+	Name() string
+	// Validate returns an error if u isn't a valid URL for the scheme.
+	// It's called when configs are validated.
+	Validate(u url.URL) error
+	// NeedsNet returns whether fetching u requires networking.
+	NeedsNet(u url.URL) bool
+	// Fetch writes the resource at u into dest, decompressing and
+	// verifying it according to opts. Schemes which can read the
+	// resource as a stream can do so with Fetcher.Copy.
+	Fetch(f *Fetcher, u url.URL, dest io.Writer, opts FetchOptions) error
+}
+
+var schemes = registry.Create("source schemes")
+
+// builtinScheme is a source scheme implemented by a Fetcher method. Its
+// URLs are validated by the validator which the config package registers
+// for it, since configs are validated without this package.
+type builtinScheme struct {
+	name     string
+	needsNet bool
+	fetch    func(f *Fetcher, u url.URL, dest io.Writer, opts FetchOptions) error
+}
+
+func (s builtinScheme) Name() string {
+	return s.name
+}
+
+func (s builtinScheme) Validate(u url.URL) error {
+	validate, ok := cutil.LookupScheme(s.name)
+	if !ok {
+		return ErrSchemeUnsupported
+	}
+	return validate(u)
+}
+
+func (s builtinScheme) NeedsNet(u url.URL) bool {
+	return s.needsNet
+}
+
+func (s builtinScheme) Fetch(f *Fetcher, u url.URL, dest io.Writer, opts FetchOptions) error {
+	return s.fetch(f, u, dest, opts)
+}
+
+// builtinSchemes returns the handlers of the schemes built into Ignition.
+func builtinSchemes() []Scheme {
+	return []Scheme{
+		builtinScheme{"http", true, (*Fetcher).fetchFromHTTPOrAzureBlob},
+		builtinScheme{"https", true, (*Fetcher).fetchFromHTTPOrAzureBlob},
+		builtinScheme{"tftp", true, (*Fetcher).fetchFromTFTP},
+		builtinScheme{"data", false, (*Fetcher).fetchFromDataURL},
+		builtinScheme{"s3", true, (*Fetcher).fetchFromS3Writer},
+		builtinScheme{"arn", true, (*Fetcher).fetchFromS3Writer},
+		builtinScheme{"gs", true, (*Fetcher).fetchFromGCS},
+		builtinScheme{"oci", true, (*Fetcher).fetchFromOCI},
+		builtinScheme{"file", false, (*Fetcher).fetchFromFile},
+		builtinScheme{"block", false, (*Fetcher).fetchFromBlockDevice},
+		vaultScheme{},
+	}
+}
+
+func init() {
+	for _, s := range builtinSchemes() {
+		schemes.Register(s)
+	}
+}
+
+// RegisterScheme adds a handler for a source scheme, so that configs may
+// use it and resources with it are fetched by the handler. It panics if
+// the scheme is already registered, including if it's built in.
+func RegisterScheme(scheme Scheme) {
+	schemes.Register(scheme)
+	cutil.RegisterScheme(scheme.Name(), scheme.Validate)
+}
+
+func getScheme(name string) Scheme {
+	if s, ok := schemes.Get(name).(Scheme); ok {
+		return s
+	}
+	return nil
+}
+
+// UrlNeedsNet returns whether fetching u requires networking.
+func UrlNeedsNet(u url.URL) bool {
+	if s := getScheme(u.Scheme); s != nil {
+		return s.NeedsNet(u)
+	}
+	return util.UrlNeedsNet(u)
+}
+
+// fetchFromScheme fetches u with the handler registered for its scheme,
+// writing the result into dest.
+func (f *Fetcher) fetchFromScheme(u url.URL, dest io.Writer, opts FetchOptions) error {
+	s := getScheme(u.Scheme)
+	if s == nil {
+		return ErrSchemeUnsupported
+	}
+	return s.Fetch(f, u, dest, opts)
+}
+
+// Copy decompresses src according to opts and writes it into dest,
+// verifying the result against opts.
+func (f *Fetcher) Copy(dest io.Writer, src io.Reader, opts FetchOptions) error {
+	return f.decompressCopyHashAndVerify(dest, src, opts)
+}
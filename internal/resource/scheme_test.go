@@ -0,0 +1,129 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha512"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	ignerrors "github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/ignition/v2/internal/log"
+	"github.com/coreos/ignition/v2/internal/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exampleScheme serves the host of example:// URLs as their contents.
+type exampleScheme struct{}
+
+func (exampleScheme) Name() string {
+	return "example"
+}
+
+func (exampleScheme) Validate(u url.URL) error {
+	if u.Host == "" {
+		return errors.New("missing host")
+	}
+	return nil
+}
+
+func (exampleScheme) NeedsNet(u url.URL) bool {
+	return false
+}
+
+func (exampleScheme) Fetch(f *Fetcher, u url.URL, dest io.Writer, opts FetchOptions) error {
+	if u.Host == "missing" {
+		return ErrNotFound
+	}
+	return f.Copy(dest, strings.NewReader(u.Host), opts)
+}
+
+// httpsScheme tries to replace a built-in scheme.
+type httpsScheme struct {
+	exampleScheme
+}
+
+func (httpsScheme) Name() string {
+	return "https"
+}
+
+func TestRegisterScheme(t *testing.T) {
+	// register into a registry local to the test, so that it can be run
+	// again
+	saved := schemes
+	schemes = registry.Create("test source schemes")
+	t.Cleanup(func() {
+		schemes = saved
+	})
+	for _, s := range builtinSchemes() {
+		schemes.Register(s)
+	}
+	schemes.Register(exampleScheme{})
+	assert.Panics(t, func() { schemes.Register(exampleScheme{}) })
+	assert.Panics(t, func() { schemes.Register(httpsScheme{}) })
+
+	u, err := url.Parse("example://hello")
+	require.NoError(t, err)
+	assert.False(t, UrlNeedsNet(*u))
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger, Offline: true}
+	sum := sha512.Sum512([]byte("hello"))
+	data, err := f.FetchToBuffer(*u, FetchOptions{
+		Hash:        sha512.New(),
+		ExpectedSum: sum[:],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data)
+
+	u, err = url.Parse("example://missing")
+	require.NoError(t, err)
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.Equal(t, ErrNotFound, err)
+
+	u, err = url.Parse("unknown://hello")
+	require.NoError(t, err)
+	f.Offline = false
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.Equal(t, ErrSchemeUnsupported, err)
+}
+
+func TestBuiltinSchemeValidate(t *testing.T) {
+	tests := []struct {
+		in  string
+		out error
+	}{
+		{"https://example.com/config.ign", nil},
+		{"file:///etc/config.ign", nil},
+		{"file://example.com/config.ign", ignerrors.ErrInvalidFileURL},
+		{"block://boot/config.ign", nil},
+		{"block://boot", ignerrors.ErrInvalidBlockURL},
+		{"s3://bucket/key?versionId=", ignerrors.ErrInvalidS3ObjectVersionId},
+		{"vault://vault.example.com/secret/data/node", nil},
+		{"vault://vault.example.com", ignerrors.ErrInvalidVaultURL},
+	}
+	for i, test := range tests {
+		u, err := url.Parse(test.in)
+		require.NoError(t, err)
+		s := getScheme(u.Scheme)
+		require.NotNil(t, s, "#%d", i)
+		assert.Equal(t, test.out, s.Validate(*u), "#%d", i)
+	}
+}
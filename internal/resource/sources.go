@@ -0,0 +1,77 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+)
+
+// ParseMirrors parses the alternative sources of a resource.
+func ParseMirrors(sources []string) ([]url.URL, error) {
+	var mirrors []url.URL
+	for _, source := range sources {
+		u, err := url.Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, *u)
+	}
+	return mirrors, nil
+}
+
+// fetchSources calls fetch with u and then with each of opts.Mirrors in
+// turn, until one succeeds. Mirrors are fetched without opts.Headers and
+// opts.Auth. If none does, ErrNeedNet is returned if any of
+// them needed networking, so that the fetch can be retried once networking
+// is available, and otherwise the last error.
+func (f *Fetcher) fetchSources(u url.URL, opts FetchOptions, fetch func(url.URL, FetchOptions) error) error {
+	err := fetch(u, opts)
+	if err == nil || len(opts.Mirrors) == 0 {
+		return err
+	}
+	// the headers and auth were declared for the primary source, so they
+	// aren't sent to mirrors, which may be run by someone else
+	mirrorOpts := opts
+	mirrorOpts.Headers = nil
+	mirrorOpts.Auth = types.Auth{}
+	mirrorOpts.authorize = nil
+	needNet := err == ErrNeedNet
+	for _, mirror := range opts.Mirrors {
+		f.Logger.Warning("failed to fetch %s: %v; trying %s", RedactedURL(u), err, RedactedURL(mirror))
+		u = mirror
+		if err = fetch(u, mirrorOpts); err == nil {
+			return nil
+		}
+		needNet = needNet || err == ErrNeedNet
+	}
+	if needNet {
+		return ErrNeedNet
+	}
+	return err
+}
+
+// resetFile empties file so that it can be fetched into again.
+func (f *Fetcher) resetFile(file *os.File) {
+	if err := file.Truncate(0); err != nil {
+		f.Logger.Warning("failed to truncate %s: %v", file.Name(), err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		f.Logger.Warning("failed to seek %s: %v", file.Name(), err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"crypto/sha512"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrors(t *testing.T) {
+	data := []byte("hello world\n")
+	sum := sha512.Sum512(data)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good":
+			_, _ = w.Write(data)
+		case "/corrupt":
+			_, _ = w.Write([]byte("hello corrupted world\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	parse := func(s string) url.URL {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		return *u
+	}
+	missing := parse(server.URL + "/missing")
+	corrupt := parse(server.URL + "/corrupt")
+	good := parse(server.URL + "/good")
+	dataURL := parse("data:,hello%20world%0A")
+
+	tests := []struct {
+		name    string
+		u       url.URL
+		mirrors []url.URL
+		offline bool
+		err     error
+	}{
+		{
+			name: "primary",
+			u:    good,
+		},
+		{
+			name:    "fallback",
+			u:       missing,
+			mirrors: []url.URL{corrupt, good},
+		},
+		{
+			name:    "all failed",
+			u:       missing,
+			mirrors: []url.URL{missing},
+			err:     ErrNotFound,
+		},
+		{
+			name:    "offline fallback",
+			u:       good,
+			mirrors: []url.URL{dataURL},
+			offline: true,
+		},
+		{
+			name:    "offline",
+			u:       missing,
+			mirrors: []url.URL{corrupt},
+			offline: true,
+			err:     ErrNeedNet,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := log.New(true)
+			f := Fetcher{Logger: &logger, Offline: test.offline}
+			opts := FetchOptions{
+				Hash:        sha512.New(),
+				ExpectedSum: sum[:],
+				Mirrors:     test.mirrors,
+			}
+
+			got, err := f.FetchToBuffer(test.u, opts)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, data, got)
+			}
+
+			dest, err := os.Create(filepath.Join(t.TempDir(), "dest"))
+			require.NoError(t, err)
+			defer dest.Close()
+			err = f.Fetch(test.u, dest, opts)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+				return
+			}
+			require.NoError(t, err)
+			got, err = os.ReadFile(dest.Name())
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestMirrorsWithoutCredentials(t *testing.T) {
+	data := []byte("hello world\n")
+	var primaryAuth, mirrorAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/primary":
+			primaryAuth = r.Header.Get("Authorization")
+			http.NotFound(w, r)
+		case "/mirror":
+			mirrorAuth = r.Header.Get("Authorization")
+			_, _ = w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	primary, err := url.Parse(server.URL + "/primary")
+	require.NoError(t, err)
+	mirror, err := url.Parse(server.URL + "/mirror")
+	require.NoError(t, err)
+
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	opts := FetchOptions{
+		Headers: http.Header{"Authorization": []string{"Bearer secret"}},
+		Mirrors: []url.URL{*mirror},
+	}
+	got, err := f.FetchToBuffer(*primary, opts)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Equal(t, "Bearer secret", primaryAuth)
+	assert.Empty(t, mirrorAuth)
+}
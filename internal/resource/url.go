@@ -16,8 +16,10 @@ package resource
 
 import (
 	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"context"
+	"crypto/sha512"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -28,6 +30,7 @@ import (
 	"net/netip"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
@@ -49,7 +52,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pin/tftp"
+	"github.com/ulikunitz/xz"
 	"github.com/vincent-petithory/dataurl"
 )
 
@@ -116,6 +121,11 @@ type Fetcher struct {
 	// network"-related errors to ErrNeedNet. That way, distro integrators
 	// could distinguish between "partial" and full network bring-up.
 	Offline bool
+
+	// CacheDir, if set, is the directory in which to cache resources with
+	// a verification hash. Cached resources are reused by later fetches
+	// with the same hash, avoiding another download.
+	CacheDir string
 }
 
 type FetchOptions struct {
@@ -131,6 +141,15 @@ type FetchOptions struct {
 	// nil, this field is ignored.
 	ExpectedSum []byte
 
+	// TransportHash is the hash to use when calculating the hash of the
+	// fetched bytes, before decompression. If left as nil, no transport hash
+	// will be calculated.
+	TransportHash hash.Hash
+
+	// The expected sum to be produced by TransportHash. If the TransportHash
+	// field is nil, this field is ignored.
+	ExpectedTransportSum []byte
+
 	// Compression specifies the type of compression to use when decompressing
 	// the fetched object. If left empty, no decompression will be used.
 	Compression string
@@ -146,48 +165,73 @@ type FetchOptions struct {
 	// List of HTTP codes to retry that usually would be considered as complete.
 	// Status codes >= 500 are always retried.
 	RetryCodes []int
+
+	// Auth specifies how to authenticate http(s) requests. It has no effect
+	// on other fetching schemes.
+	Auth types.Auth
+
+	// Mirrors are alternative URLs of the resource, tried in order if
+	// fetching it from the given URL fails. The other options apply to
+	// every URL, except Headers and Auth, which are only used for the
+	// given URL.
+	Mirrors []url.URL
+
+	// Sensitive marks a resource holding secrets, which is never shared
+	// with peers.
+	Sensitive bool
+
+	// authorize, if set, is called to authenticate each http request
+	// before it is sent.
+	authorize func(*http.Request) error
+
+	// body, if set, is sent as the body of each http request.
+	body []byte
+
+	// reportHash, if set, hashes the fetched contents, after
+	// decompression, for the report.
+	reportHash hash.Hash
+}
+
+// contentHashers resets the hashes of the fetched contents, after
+// decompression, which are set in opts, and returns them.
+func (opts FetchOptions) contentHashers() []io.Writer {
+	var hashers []io.Writer
+	for _, h := range []hash.Hash{opts.Hash, opts.reportHash} {
+		if h != nil {
+			h.Reset()
+			hashers = append(hashers, h)
+		}
+	}
+	return hashers
 }
 
 // FetchToBuffer will fetch the given url into a temporary file, and then read
 // in the contents of the file and delete it. It will return the downloaded
 // contents, or an error if one was encountered.
 func (f *Fetcher) FetchToBuffer(u url.URL, opts FetchOptions) ([]byte, error) {
-	if f.Offline && util.UrlNeedsNet(u) {
+	var data []byte
+	err := f.fetchSources(u, opts, func(u url.URL, opts FetchOptions) error {
+		var err error
+		data, err = f.fetchToBufferWithCache(u, opts)
+		if err == nil && f.recording() && u.Scheme != "" {
+			sum := sha512.Sum512(data)
+			f.Logger.RecordFetch(RedactedURL(u), "sha512-"+hex.EncodeToString(sum[:]))
+		}
+		return err
+	})
+	return data, err
+}
+
+func (f *Fetcher) fetchToBuffer(u url.URL, opts FetchOptions) ([]byte, error) {
+	if f.Offline && UrlNeedsNet(u) {
 		return nil, ErrNeedNet
 	}
 
-	var err error
-	dest := new(bytes.Buffer)
-	switch u.Scheme {
-	case "http", "https":
-		isAzureBlob := strings.HasSuffix(u.Host, ".blob.core.windows.net")
-		if f.AzSession != nil && isAzureBlob {
-			err = f.fetchFromAzureBlob(u, dest, opts)
-			if err != nil {
-				f.Logger.Info("could not fetch %s via Azure credentials: %v", u.String(), err)
-				f.Logger.Info("falling back to HTTP fetch")
-			}
-		}
-		if !isAzureBlob || f.AzSession == nil || err != nil {
-			err = f.fetchFromHTTP(u, dest, opts)
-		}
-	case "tftp":
-		err = f.fetchFromTFTP(u, dest, opts)
-	case "data":
-		err = f.fetchFromDataURL(u, dest, opts)
-	case "s3", "arn":
-		buf := &s3buf{
-			WriteAtBuffer: manager.NewWriteAtBuffer([]byte{}),
-		}
-		err = f.fetchFromS3(u, buf, opts)
-		return buf.Bytes(), err
-	case "gs":
-		err = f.fetchFromGCS(u, dest, opts)
-	case "":
+	if u.Scheme == "" {
 		return nil, nil
-	default:
-		return nil, ErrSchemeUnsupported
 	}
+	dest := new(bytes.Buffer)
+	err := f.fetchFromScheme(u, dest, opts)
 	return dest.Bytes(), err
 }
 
@@ -224,40 +268,73 @@ func (s *s3buf) Seek(offset int64, whence int) (int64, error) {
 // at the beginning. Since some url schemes (ex: s3) use chunked downloads and
 // fetch chunks out of order, Fetch's behavior when dest is not an empty file is
 // undefined.
+//
+// If the resource has a verification hash and a cache directory is set, a
+// cached copy is used when it matches, and fetched resources are cached.
+//
+// If opts.Mirrors is set, they are tried in turn if fetching from u fails.
 func (f *Fetcher) Fetch(u url.URL, dest *os.File, opts FetchOptions) error {
-	if f.Offline && util.UrlNeedsNet(u) {
-		return ErrNeedNet
+	if f.recording() {
+		// hash the contents for the report as they're written
+		opts.reportHash = sha512.New()
 	}
-	var err error
-	switch u.Scheme {
-	case "http", "https":
-		isAzureBlob := strings.HasSuffix(u.Host, ".blob.core.windows.net")
-		if f.AzSession != nil && isAzureBlob {
-			err = f.fetchFromAzureBlob(u, dest, opts)
-			if err != nil {
-				f.Logger.Info("could not fetch %s via Azure credentials: %v", u.String(), err)
-				f.Logger.Info("falling back to HTTP fetch")
-			}
-		}
-		if !isAzureBlob || f.AzSession == nil || err != nil {
-			err = f.fetchFromHTTP(u, dest, opts)
+	return f.fetchSources(u, opts, func(u url.URL, opts FetchOptions) error {
+		if err := f.fetchWithCache(u, dest, opts); err != nil {
+			f.resetFile(dest)
+			return err
 		}
-		return err
-	case "tftp":
-		return f.fetchFromTFTP(u, dest, opts)
-	case "data":
-		return f.fetchFromDataURL(u, dest, opts)
-	case "s3", "arn":
-		return f.fetchFromS3(u, dest, opts)
-	case "gs":
-		return f.fetchFromGCS(u, dest, opts)
-	case "":
+		f.recordFetch(u, opts)
 		return nil
-	default:
-		return ErrSchemeUnsupported
+	})
+}
+
+// recordFetch records the fetch of u for the report, with the hash computed
+// by opts.reportHash.
+func (f *Fetcher) recordFetch(u url.URL, opts FetchOptions) {
+	if opts.reportHash != nil && u.Scheme != "" {
+		f.Logger.RecordFetch(RedactedURL(u), "sha512-"+hex.EncodeToString(opts.reportHash.Sum(nil)))
 	}
 }
 
+func (f *Fetcher) fetch(u url.URL, dest *os.File, opts FetchOptions) error {
+	if f.Offline && UrlNeedsNet(u) {
+		return ErrNeedNet
+	}
+	if u.Scheme == "" {
+		return nil
+	}
+	return f.fetchFromScheme(u, dest, opts)
+}
+
+// recording returns whether fetches should be recorded for the report.
+func (f *Fetcher) recording() bool {
+	return f.Logger != nil && f.Logger.Recorder() != nil
+}
+
+// RedactedURL returns u in a form suitable for logs and reports: any
+// password is masked, and the contents of data URLs are omitted.
+func RedactedURL(u url.URL) string {
+	if u.Scheme == "data" {
+		return "data:"
+	}
+	return u.Redacted()
+}
+
+// fetchFromHTTPOrAzureBlob fetches u via HTTP, or with Azure credentials if
+// it's an Azure blob and the Fetcher has an Azure session, falling back to
+// HTTP if that fails.
+func (f *Fetcher) fetchFromHTTPOrAzureBlob(u url.URL, dest io.Writer, opts FetchOptions) error {
+	if f.AzSession != nil && strings.HasSuffix(u.Host, ".blob.core.windows.net") {
+		err := f.fetchFromAzureBlob(u, dest, opts)
+		if err == nil {
+			return nil
+		}
+		f.Logger.Info("could not fetch %s via Azure credentials: %v", u.String(), err)
+		f.Logger.Info("falling back to HTTP fetch")
+	}
+	return f.fetchFromHTTP(u, dest, opts)
+}
+
 // FetchFromTFTP fetches a resource from u via TFTP into dest, returning an
 // error if one is encountered.
 func (f *Fetcher) fetchFromTFTP(u url.URL, dest io.Writer, opts FetchOptions) error {
@@ -268,7 +345,23 @@ func (f *Fetcher) fetchFromTFTP(u url.URL, dest io.Writer, opts FetchOptions) er
 	if err != nil {
 		return err
 	}
-	wt, err := c.Receive(u.Path, "octet")
+
+	ctx := context.Background()
+	if f.client != nil && f.client.timeout != 0 {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithTimeout(ctx, f.client.timeout)
+		defer cancelFn()
+	}
+	retry := f.retryPolicy()
+	if !retry.configured {
+		// the TFTP client already retries timed out packets
+		retry.maxAttempts = 1
+	}
+	var wt io.WriterTo
+	err = retry.do(ctx, f, "TFTP "+u.String(), retry.retryNetError, func() (err error) {
+		wt, err = c.Receive(u.Path, "octet")
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -358,12 +451,6 @@ func (f *Fetcher) fetchFromHTTP(u url.URL, dest io.Writer, opts FetchOptions) er
 		f.client.transport.DialContext = d.DialContext
 	}
 
-	// We do not want to redirect HTTP headers
-	f.client.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		req.Header = make(http.Header)
-		return nil
-	}
-
 	// TODO use .Clone() when we have a new enough golang
 	// (With Rust, we'd have immutability and wouldn't need to defensively clone)
 	headers := make(http.Header)
@@ -380,6 +467,15 @@ func (f *Fetcher) fetchFromHTTP(u url.URL, dest io.Writer, opts FetchOptions) er
 
 	requestOpts := opts
 	requestOpts.Headers = headers
+	if opts.Auth.IsPresent() {
+		authorize, err := f.authorizer(u, opts.Auth)
+		if err != nil {
+			return err
+		}
+		requestOpts.authorize = authorize
+	}
+	limits := f.limits()
+	defer limits.acquire()()
 	dataReader, status, ctxCancel, err := f.client.httpReaderWithHeader(requestOpts, u.String())
 	if ctxCancel != nil {
 		// whatever context getReaderWithHeader created for the request should
@@ -402,7 +498,7 @@ func (f *Fetcher) fetchFromHTTP(u url.URL, dest io.Writer, opts FetchOptions) er
 		return ErrFailed
 	}
 
-	return f.decompressCopyHashAndVerify(dest, dataReader, opts)
+	return f.decompressCopyHashAndVerify(dest, limits.reader(dataReader), opts)
 }
 
 // FetchFromDataURL writes the data stored in the dataurl u into dest, returning
@@ -464,6 +560,23 @@ type s3target interface {
 	io.ReadSeeker
 }
 
+// fetchFromS3Writer fetches u from S3 into dest. The S3 downloader writes
+// chunks out of order, so unless dest is seekable the object is buffered in
+// memory first.
+func (f *Fetcher) fetchFromS3Writer(u url.URL, dest io.Writer, opts FetchOptions) error {
+	if target, ok := dest.(s3target); ok {
+		return f.fetchFromS3(u, target, opts)
+	}
+	buf := &s3buf{
+		WriteAtBuffer: manager.NewWriteAtBuffer([]byte{}),
+	}
+	if err := f.fetchFromS3(u, buf, opts); err != nil {
+		return err
+	}
+	_, err := dest.Write(buf.Bytes())
+	return err
+}
+
 // FetchFromS3 gets data from an S3 bucket as described by u and writes it into
 // dest, returning an error if one is encountered. It will attempt to acquire
 // IAM credentials from the EC2 metadata service, and if this fails will attempt
@@ -530,6 +643,7 @@ func (f *Fetcher) fetchFromS3(u url.URL, dest s3target, opts FetchOptions) error
 		tmpClient := s3.NewFromConfig(cfg, func(o *s3.Options) {
 			o.Region = regionHint
 			o.HTTPClient = f.client.client
+			f.setS3Retryer(o)
 		})
 		r, err := manager.GetBucketRegion(ctx, tmpClient, bucket)
 		if err != nil {
@@ -557,6 +671,7 @@ func (f *Fetcher) fetchFromS3(u url.URL, dest s3target, opts FetchOptions) error
 		o.Region = region
 		o.HTTPClient = f.client.client
 		o.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateEnabled
+		f.setS3Retryer(o)
 	})
 
 	if err := f.fetchFromS3WithClient(ctx, dest, input, client); err != nil {
@@ -568,6 +683,7 @@ func (f *Fetcher) fetchFromS3(u url.URL, dest s3target, opts FetchOptions) error
 				o.HTTPClient = f.client.client
 				o.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateEnabled
 				o.Credentials = aws.AnonymousCredentials{}
+				f.setS3Retryer(o)
 			})
 			if err2 := f.fetchFromS3WithClient(ctx, dest, input, anonClient); err2 != nil {
 				return fmt.Errorf("error fetching object %q from bucket %q anonymously: %w (authenticated fetch also failed: %w)", key, bucket, err2, err)
@@ -576,31 +692,45 @@ func (f *Fetcher) fetchFromS3(u url.URL, dest s3target, opts FetchOptions) error
 			return fmt.Errorf("error fetching object %q from bucket %q: %s", key, bucket, err.Error())
 		}
 	}
-	if opts.Hash != nil {
-		opts.Hash.Reset()
+	if opts.Hash != nil || opts.TransportHash != nil || opts.reportHash != nil {
 		_, err = dest.Seek(0, io.SeekStart)
 		if err != nil {
 			return err
 		}
-		_, err = io.Copy(opts.Hash, dest)
+		// S3 objects can't be compressed, so the transport hash is of the
+		// same bytes
+		hashers := opts.contentHashers()
+		if opts.TransportHash != nil {
+			opts.TransportHash.Reset()
+			hashers = append(hashers, opts.TransportHash)
+		}
+		_, err = io.Copy(io.MultiWriter(hashers...), dest)
 		if err != nil {
 			return err
 		}
-		calculatedSum := opts.Hash.Sum(nil)
-		if !bytes.Equal(calculatedSum, opts.ExpectedSum) {
-			return util.ErrHashMismatch{
-				Calculated: hex.EncodeToString(calculatedSum),
-				Expected:   hex.EncodeToString(opts.ExpectedSum),
-			}
+		if err := f.verifyTransportSum(opts); err != nil {
+			return err
+		}
+		if err := f.verifySum(opts); err != nil {
+			return err
 		}
-		f.Logger.Debug("file matches expected sum of: %s", hex.EncodeToString(opts.ExpectedSum))
 	}
 	return nil
 }
 
+// setS3Retryer configures the S3 client to follow the retry policy, if one
+// was configured. Otherwise the SDK's default is used.
+func (f *Fetcher) setS3Retryer(o *s3.Options) {
+	if retry := f.retryPolicy(); retry.configured {
+		o.Retryer = retry.awsRetryer()
+	}
+}
+
 func (f *Fetcher) fetchFromS3WithClient(ctx context.Context, dest s3target, input *s3.GetObjectInput, client *s3.Client) error {
-	downloader := manager.NewDownloader(client)     //nolint:staticcheck // SA1019: migration to transfermanager tracked separately
-	_, err := downloader.Download(ctx, dest, input) //nolint:staticcheck // SA1019: see above
+	limits := f.limits()
+	defer limits.acquire()()
+	downloader := manager.NewDownloader(client)                      //nolint:staticcheck // SA1019: migration to transfermanager tracked separately
+	_, err := downloader.Download(ctx, limits.s3target(dest), input) //nolint:staticcheck // SA1019: see above
 	return err
 }
 
@@ -630,12 +760,25 @@ func (f *Fetcher) fetchFromAzureBlob(u url.URL, dest io.Writer, opts FetchOption
 	}
 
 	// Create Azure Blob Storage client
-	storageClient, err := azblob.NewClient(storageAccount, f.AzSession, nil)
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return err
+		}
+	}
+	// Use the HTTP client so the proxy and TLS settings apply
+	clientOptions := &azblob.ClientOptions{}
+	clientOptions.Transport = f.client.client
+	if retry := f.retryPolicy(); retry.configured {
+		clientOptions.Retry = retry.azureRetryOptions()
+	}
+	storageClient, err := azblob.NewClient(storageAccount, f.AzSession, clientOptions)
 	if err != nil {
 		f.Logger.Debug("failed to create azblob client: %v", err)
 		return fmt.Errorf("failed to create azblob client: %w", err)
 	}
 
+	limits := f.limits()
+	defer limits.acquire()()
 	downloadStream, err := storageClient.DownloadStream(ctx, container, file, nil)
 	if err != nil {
 		return fmt.Errorf("failed to download blob from container '%s', file '%s': %w", container, file, err)
@@ -645,7 +788,7 @@ func (f *Fetcher) fetchFromAzureBlob(u url.URL, dest io.Writer, opts FetchOption
 	}()
 
 	// Process the downloaded blob
-	err = f.decompressCopyHashAndVerify(dest, downloadStream.Body, opts)
+	err = f.decompressCopyHashAndVerify(dest, limits.reader(downloadStream.Body), opts)
 	if err != nil {
 		f.Logger.Debug("Error processing downloaded blob: %v", err)
 		return fmt.Errorf("failed to process downloaded blob: %w", err)
@@ -662,6 +805,20 @@ func (f *Fetcher) uncompress(r io.Reader, opts FetchOptions) (io.ReadCloser, err
 		return io.NopCloser(r), nil
 	case "gzip":
 		return gzip.NewReader(r)
+	case "zstd":
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case "xz":
+		decompressor, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(decompressor), nil
+	case "bzip2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
 	default:
 		return nil, configErrors.ErrCompressionInvalid
 	}
@@ -670,9 +827,76 @@ func (f *Fetcher) uncompress(r io.Reader, opts FetchOptions) (io.ReadCloser, err
 // decompressCopyHashAndVerify will decompress src if necessary, copy src into
 // dest until src returns an io.EOF while also calculating a hash if one is set,
 // and will return an error if there's any problems with any of this or if the
-// hash doesn't match the expected hash in the opts.
+// hash doesn't match the expected hash in the opts. If a transport hash is set,
+// the fetched data in src is hashed too, and checked before the decompressed
+// data.
 func (f *Fetcher) decompressCopyHashAndVerify(dest io.Writer, src io.Reader, opts FetchOptions) error {
-	var decompressor io.ReadCloser
+	if opts.TransportHash != nil {
+		staged, cleanup, err := f.stageAndVerifyTransportSum(dest, src, opts)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		src = staged
+	}
+	if err := f.decompressCopy(dest, src, opts); err != nil {
+		return err
+	}
+	return f.verifySum(opts)
+}
+
+// stageAndVerifyTransportSum stages src, checking it against the expected
+// transport sum, so that data which doesn't match is never decompressed.
+// If dest is a regular file, src is staged in a temporary file next to it,
+// rather than in the initramfs's /tmp, which is held in memory. Otherwise
+// it's staged in memory, as dest presumably is. The caller must call the
+// returned cleanup function once it's read the staged data.
+func (f *Fetcher) stageAndVerifyTransportSum(dest io.Writer, src io.Reader, opts FetchOptions) (io.Reader, func(), error) {
+	file, ok := dest.(*os.File)
+	if ok {
+		st, err := file.Stat()
+		ok = err == nil && st.Mode().IsRegular()
+	}
+	if !ok {
+		var staged bytes.Buffer
+		if err := f.copyAndVerifyTransportSum(&staged, src, opts); err != nil {
+			return nil, nil, err
+		}
+		return &staged, func() {}, nil
+	}
+
+	staged, err := os.CreateTemp(filepath.Dir(file.Name()), "tmp")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		_ = staged.Close()
+		_ = os.Remove(staged.Name())
+	}
+	if err := f.copyAndVerifyTransportSum(staged, src, opts); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return staged, cleanup, nil
+}
+
+// copyAndVerifyTransportSum copies src into dest, and checks it against
+// the expected transport sum.
+func (f *Fetcher) copyAndVerifyTransportSum(dest io.Writer, src io.Reader, opts FetchOptions) error {
+	opts.TransportHash.Reset()
+	if _, err := io.Copy(io.MultiWriter(dest, opts.TransportHash), src); err != nil {
+		return err
+	}
+	return f.verifyTransportSum(opts)
+}
+
+// decompressCopy decompresses src into dest, hashing the decompressed data
+// if a hash or report hash is set.
+func (f *Fetcher) decompressCopy(dest io.Writer, src io.Reader, opts FetchOptions) error {
 	decompressor, err := f.uncompress(src, opts)
 	if err != nil {
 		return err
@@ -680,23 +904,44 @@ func (f *Fetcher) decompressCopyHashAndVerify(dest io.Writer, src io.Reader, opt
 	defer func() {
 		_ = decompressor.Close()
 	}()
-	if opts.Hash != nil {
-		opts.Hash.Reset()
-		dest = io.MultiWriter(dest, opts.Hash)
+	if hashers := opts.contentHashers(); len(hashers) > 0 {
+		dest = io.MultiWriter(append([]io.Writer{dest}, hashers...)...)
 	}
-	if _, err = io.Copy(dest, decompressor); err != nil {
-		return err
+	_, err = io.Copy(dest, decompressor)
+	return err
+}
+
+// verifySum checks the sum of opts.Hash, if it's set, against the expected
+// sum.
+func (f *Fetcher) verifySum(opts FetchOptions) error {
+	if opts.Hash == nil {
+		return nil
 	}
-	if opts.Hash != nil {
-		calculatedSum := opts.Hash.Sum(nil)
-		if !bytes.Equal(calculatedSum, opts.ExpectedSum) {
-			return util.ErrHashMismatch{
-				Calculated: hex.EncodeToString(calculatedSum),
-				Expected:   hex.EncodeToString(opts.ExpectedSum),
-			}
+	calculatedSum := opts.Hash.Sum(nil)
+	if !bytes.Equal(calculatedSum, opts.ExpectedSum) {
+		return util.ErrHashMismatch{
+			Calculated: hex.EncodeToString(calculatedSum),
+			Expected:   hex.EncodeToString(opts.ExpectedSum),
 		}
-		f.Logger.Debug("file matches expected sum of: %s", hex.EncodeToString(opts.ExpectedSum))
 	}
+	f.Logger.Debug("file matches expected sum of: %s", hex.EncodeToString(opts.ExpectedSum))
+	return nil
+}
+
+// verifyTransportSum checks the sum of opts.TransportHash, if it's set,
+// against the expected transport sum.
+func (f *Fetcher) verifyTransportSum(opts FetchOptions) error {
+	if opts.TransportHash == nil {
+		return nil
+	}
+	calculatedSum := opts.TransportHash.Sum(nil)
+	if !bytes.Equal(calculatedSum, opts.ExpectedTransportSum) {
+		return fmt.Errorf("transport %w", util.ErrHashMismatch{
+			Calculated: hex.EncodeToString(calculatedSum),
+			Expected:   hex.EncodeToString(opts.ExpectedTransportSum),
+		})
+	}
+	f.Logger.Debug("fetched data matches expected transport sum of: %s", hex.EncodeToString(opts.ExpectedTransportSum))
 	return nil
 }
 
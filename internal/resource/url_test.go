@@ -17,15 +17,20 @@ package resource
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
 	"github.com/coreos/ignition/v2/config/shared/errors"
@@ -96,6 +101,42 @@ func TestDataUrl(t *testing.T) {
 				Expected:   "807e8ff949e61d23f5ee42a629ec96e9fc526b62f030cd70ba2cd5b9d97935461eacc29bf58bcd0426e9e1fdb0eda939603ed52c9c06d0712208a15cd582c600",
 			}},
 		},
+		// data url, zstd
+		{
+			in: in{
+				url: "data:,%28%B5%2F%FD%04%00i%00%00example%20file%0A%E0%BD%B0%E7",
+				opts: FetchOptions{
+					Compression: "zstd",
+					// digest of decompressed data
+					ExpectedSum: []byte("\x80\x7e\x8f\xf9\x49\xe6\x1d\x23\xf5\xee\x42\xa6\x29\xec\x96\xe9\xfc\x52\x6b\x62\xf0\x30\xcd\x70\xba\x2c\xd5\xb9\xd9\x79\x35\x46\x1e\xac\xc2\x9b\xf5\x8b\xcd\x04\x26\xe9\xe1\xfd\xb0\xed\xa9\x39\x60\x3e\xd5\x2c\x9c\x06\xd0\x71\x22\x08\xa1\x5c\xd5\x82\xc6\x0e"),
+				},
+			},
+			out: out{data: []byte("example file\n")},
+		},
+		// data url, xz
+		{
+			in: in{
+				url: "data:,%FD7zXZ%00%00%04%E6%D6%B4F%02%00%21%01%16%00%00%00t%2F%E5%A3%01%00%0Cexample%20file%0A%00%00%00%00%22f%C8%82%2C%F4%89%E4%00%01%25%0Dq%19%C4%B6%1F%B6%F3%7D%01%00%00%00%00%04YZ",
+				opts: FetchOptions{
+					Compression: "xz",
+					// digest of decompressed data
+					ExpectedSum: []byte("\x80\x7e\x8f\xf9\x49\xe6\x1d\x23\xf5\xee\x42\xa6\x29\xec\x96\xe9\xfc\x52\x6b\x62\xf0\x30\xcd\x70\xba\x2c\xd5\xb9\xd9\x79\x35\x46\x1e\xac\xc2\x9b\xf5\x8b\xcd\x04\x26\xe9\xe1\xfd\xb0\xed\xa9\x39\x60\x3e\xd5\x2c\x9c\x06\xd0\x71\x22\x08\xa1\x5c\xd5\x82\xc6\x0e"),
+				},
+			},
+			out: out{data: []byte("example file\n")},
+		},
+		// data url, bzip2
+		{
+			in: in{
+				url: "data:,BZh91AY&SY%DF%E0%C82%00%00%02%D1%80%00%10@%00%23&@@%20%001%000%1A%1A%1AhC@%DE%A7%CD%F8%BB%92%29%C2%84%86%FF%06A%90",
+				opts: FetchOptions{
+					Compression: "bzip2",
+					// digest of decompressed data
+					ExpectedSum: []byte("\x80\x7e\x8f\xf9\x49\xe6\x1d\x23\xf5\xee\x42\xa6\x29\xec\x96\xe9\xfc\x52\x6b\x62\xf0\x30\xcd\x70\xba\x2c\xd5\xb9\xd9\x79\x35\x46\x1e\xac\xc2\x9b\xf5\x8b\xcd\x04\x26\xe9\xe1\xfd\xb0\xed\xa9\x39\x60\x3e\xd5\x2c\x9c\x06\xd0\x71\x22\x08\xa1\x5c\xd5\x82\xc6\x0e"),
+				},
+			},
+			out: out{data: []byte("example file\n")},
+		},
 		// data url, invalid compressed data
 		{
 			in: in{
@@ -142,6 +183,142 @@ func TestDataUrl(t *testing.T) {
 	}
 }
 
+func TestRecordFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world\n"))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	serverURL.User = url.UserPassword("user", "secret")
+	serverURL.Path = "/file"
+
+	logger := log.New(true)
+	recorder := &log.Recorder{}
+	logger.SetRecorder(recorder)
+	f := Fetcher{
+		Logger: &logger,
+	}
+
+	dataURL, err := url.Parse("data:,hello%20world%0a")
+	assert.NoError(t, err)
+	_, err = f.FetchToBuffer(*dataURL, FetchOptions{})
+	assert.NoError(t, err)
+
+	dest, err := os.CreateTemp(t.TempDir(), "fetch")
+	assert.NoError(t, err)
+	defer func() {
+		_ = dest.Close()
+	}()
+	err = f.Fetch(*serverURL, dest, FetchOptions{})
+	assert.NoError(t, err)
+
+	hash := "sha512-db3974a97f2407b7cae1ae637c0030687a11913274d578492558e39c16c017de84eacdc8c62fe34ee4e12b4b1428817f09b6a2760c3f8a664ceae94d2434a593"
+	redacted := *serverURL
+	redacted.User = url.UserPassword("user", "xxxxx")
+	assert.Equal(t, []log.Fetch{
+		{URL: "data:", Hash: hash},
+		{URL: redacted.String(), Hash: hash},
+	}, recorder.Fetches)
+}
+
+func TestTransportHash(t *testing.T) {
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write([]byte("hello world\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	u, err := url.Parse("data:;base64," + base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	assert.NoError(t, err)
+	transportSum := sha256.Sum256(compressed.Bytes())
+	contentSum := sha512.Sum512([]byte("hello world\n"))
+
+	// both the compressed and decompressed data are verified
+	data, err := f.FetchToBuffer(*u, FetchOptions{
+		Compression:          "gzip",
+		Hash:                 sha512.New(),
+		ExpectedSum:          contentSum[:],
+		TransportHash:        sha256.New(),
+		ExpectedTransportSum: transportSum[:],
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+
+	// the transport hash is checked first
+	badSum := sha256.Sum256([]byte("hello world\n"))
+	_, err = f.FetchToBuffer(*u, FetchOptions{
+		Compression:          "gzip",
+		Hash:                 sha512.New(),
+		ExpectedSum:          make([]byte, sha512.Size),
+		TransportHash:        sha256.New(),
+		ExpectedTransportSum: badSum[:],
+	})
+	var mismatch util.ErrHashMismatch
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, fmt.Sprintf("%x", transportSum), mismatch.Calculated)
+
+	// nothing is decompressed if the transport hash doesn't match
+	var dest bytes.Buffer
+	err = f.decompressCopyHashAndVerify(&dest, bytes.NewReader(compressed.Bytes()), FetchOptions{
+		Compression:          "gzip",
+		TransportHash:        sha256.New(),
+		ExpectedTransportSum: badSum[:],
+	})
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Zero(t, dest.Len())
+
+	// so data which isn't even valid gzip fails the transport hash
+	garbage := []byte("not gzip")
+	garbageSum := sha256.Sum256(garbage)
+	err = f.decompressCopyHashAndVerify(&dest, bytes.NewReader(garbage), FetchOptions{
+		Compression:          "gzip",
+		TransportHash:        sha256.New(),
+		ExpectedTransportSum: transportSum[:],
+	})
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, fmt.Sprintf("%x", garbageSum), mismatch.Calculated)
+
+	// fetches into a file are staged next to it, not in the system's
+	// temporary directory, and nothing is left behind
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	dir := t.TempDir()
+	file, err := os.CreateTemp(dir, "dest")
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+	require.NoError(t, f.Fetch(*u, file, FetchOptions{
+		Compression:          "gzip",
+		Hash:                 sha512.New(),
+		ExpectedSum:          contentSum[:],
+		TransportHash:        sha256.New(),
+		ExpectedTransportSum: transportSum[:],
+	}))
+	contents, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(contents))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestS3CompressionUnsupported(t *testing.T) {
+	logger := log.New(true)
+	f := Fetcher{
+		Logger: &logger,
+	}
+	u, err := url.Parse("s3://bucket/key")
+	assert.NoError(t, err)
+	for _, compression := range []string{"gzip", "zstd", "xz", "bzip2"} {
+		_, err := f.FetchToBuffer(*u, FetchOptions{Compression: compression})
+		assert.Equal(t, ErrCompressionUnsupported, err, compression)
+	}
+}
+
 func TestFetchOffline(t *testing.T) {
 	type in struct {
 		url  string
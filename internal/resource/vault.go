@@ -0,0 +1,332 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	configErrors "github.com/coreos/ignition/v2/config/shared/errors"
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+)
+
+// maxVaultResponseSize bounds the size of a Vault response we'll read into
+// memory.
+const maxVaultResponseSize = 16 << 20
+
+var ErrVaultNotConfigured = errors.New("vault sources require ignition.security.vault to be configured")
+
+// vaultScheme fetches vault:// URLs: fields of secrets in Vault KV engines,
+// and ciphertexts decrypted with Vault Transit keys.
+type vaultScheme struct{}
+
+func (vaultScheme) Name() string {
+	return "vault"
+}
+
+func (vaultScheme) Validate(u url.URL) error {
+	_, err := cutil.ParseVaultURL(u)
+	return err
+}
+
+func (vaultScheme) NeedsNet(u url.URL) bool {
+	return true
+}
+
+func (vaultScheme) Fetch(f *Fetcher, u url.URL, dest io.Writer, opts FetchOptions) error {
+	return f.fetchFromVault(u, dest, opts)
+}
+
+// vaultSession holds the Vault authentication config and the tokens
+// obtained by logging in to each Vault server.
+type vaultSession struct {
+	config types.Vault
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// vaultResponse is the envelope of a Vault API response.
+type vaultResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Auth   *vaultAuth      `json:"auth"`
+	Errors []string        `json:"errors"`
+}
+
+type vaultAuth struct {
+	ClientToken string `json:"client_token"`
+}
+
+// UpdateVault sets the config used to authenticate to Vault for vault
+// sources. Tokens from earlier logins are kept if the config is unchanged.
+func (f *Fetcher) UpdateVault(vault types.Vault) error {
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return err
+		}
+	}
+	if !vault.IsPresent() {
+		f.client.vault = nil
+		return nil
+	}
+	if f.client.vault != nil && reflect.DeepEqual(f.client.vault.config, vault) {
+		return nil
+	}
+	f.client.vault = &vaultSession{
+		config: vault,
+		tokens: make(map[string]string),
+	}
+	return nil
+}
+
+// fetchFromVault fetches a field of a secret from a Vault KV engine, or
+// decrypts a ciphertext with a Transit key, after logging in with the
+// configured auth method.
+func (f *Fetcher) fetchFromVault(u url.URL, dest io.Writer, opts FetchOptions) error {
+	ref, err := cutil.ParseVaultURL(u)
+	if err != nil {
+		return err
+	}
+	if f.client == nil {
+		if err := f.newHttpClient(); err != nil {
+			return err
+		}
+	}
+	if f.client.vault == nil {
+		return ErrVaultNotConfigured
+	}
+	base := vaultBaseURL(ref.Address)
+	token, err := f.vaultToken(base, opts)
+	if err != nil {
+		return err
+	}
+
+	var secret []byte
+	if ref.Ciphertext != "" {
+		secret, err = f.vaultDecrypt(base, token, ref, opts)
+	} else {
+		secret, err = f.vaultRead(base, token, ref, opts)
+	}
+	if err != nil {
+		return err
+	}
+	f.Logger.AddSecrets(string(secret))
+	return f.decompressCopyHashAndVerify(dest, bytes.NewReader(secret), opts)
+}
+
+// vaultBaseURL returns the URL of the Vault API. As with registries, we
+// use plain HTTP for servers on the loopback interface, such as a dev-mode
+// server.
+func vaultBaseURL(address string) string {
+	if isLoopback(address) {
+		return "http://" + address + "/v1/"
+	}
+	return "https://" + address + "/v1/"
+}
+
+// vaultToken returns a token for the Vault server, logging in with the
+// configured auth method if we don't have one yet.
+func (f *Fetcher) vaultToken(base string, opts FetchOptions) (string, error) {
+	s := f.client.vault
+	s.mu.Lock()
+	token, ok := s.tokens[base]
+	s.mu.Unlock()
+	if ok {
+		return token, nil
+	}
+
+	// the credentials are fetched without holding the lock, since they may
+	// themselves be fetched in parallel with other resources
+	var mount string
+	var body map[string]string
+	switch *s.config.AuthMethod {
+	case "approle":
+		secretID, err := f.FetchResource(s.config.SecretID)
+		if err != nil {
+			return "", fmt.Errorf("fetching Vault secretId: %w", err)
+		}
+		mount = "approle"
+		body = map[string]string{
+			"role_id":   *s.config.RoleID,
+			"secret_id": strings.TrimSpace(string(secretID)),
+		}
+		f.Logger.AddSecrets(body["secret_id"])
+	case "jwt":
+		jwt, err := f.FetchResource(s.config.Jwt)
+		if err != nil {
+			return "", fmt.Errorf("fetching Vault jwt: %w", err)
+		}
+		mount = "jwt"
+		body = map[string]string{
+			"role": *s.config.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+		f.Logger.AddSecrets(body["jwt"])
+	default:
+		// this is also already checked at validation time
+		return "", configErrors.ErrVaultAuthMethodInvalid
+	}
+	if cutil.NotEmpty(s.config.AuthMount) {
+		mount = strings.Trim(*s.config.AuthMount, "/")
+	}
+
+	resp, err := f.vaultRequest(base+"auth/"+url.PathEscape(mount)+"/login", "", body, opts)
+	if err != nil {
+		return "", fmt.Errorf("logging in to Vault: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("logging in to Vault: no token returned")
+	}
+	f.Logger.AddSecrets(resp.Auth.ClientToken)
+
+	s.mu.Lock()
+	s.tokens[base] = resp.Auth.ClientToken
+	s.mu.Unlock()
+	return resp.Auth.ClientToken, nil
+}
+
+// vaultRead reads a field of a secret from a KV engine. Version 2 engines
+// nest the secret's data and return its metadata alongside.
+func (f *Fetcher) vaultRead(base, token string, ref cutil.VaultReference, opts FetchOptions) ([]byte, error) {
+	u := base + vaultEscapePath(ref.Path)
+	if ref.Version != "" {
+		u += "?version=" + ref.Version
+	}
+	resp, err := f.vaultRequest(u, token, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("parsing Vault secret: %v", err)
+	}
+	if nested, ok := data["data"]; ok && data["metadata"] != nil {
+		data = nil
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return nil, fmt.Errorf("parsing Vault secret: %v", err)
+		}
+	}
+
+	field := ref.Field
+	if field == "" {
+		if len(data) != 1 {
+			return nil, fmt.Errorf("secret has %d fields; specify one with the field parameter", len(data))
+		}
+		for k := range data {
+			field = k
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return nil, fmt.Errorf("secret has no field %q", field)
+	}
+	// string values are returned as is, and others as JSON
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return []byte(s), nil
+	}
+	return value, nil
+}
+
+// vaultDecrypt decrypts a ciphertext with a Transit key.
+func (f *Fetcher) vaultDecrypt(base, token string, ref cutil.VaultReference, opts FetchOptions) ([]byte, error) {
+	body := map[string]string{"ciphertext": ref.Ciphertext}
+	resp, err := f.vaultRequest(base+vaultEscapePath(ref.Path), token, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("parsing Vault decryption: %v", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("decoding Vault plaintext: %v", err)
+	}
+	return plaintext, nil
+}
+
+// vaultRequest sends a request to the Vault API, as a POST of body if it's
+// set, and returns the response. It returns an error unless the request
+// succeeds.
+func (f *Fetcher) vaultRequest(u, token string, body any, opts FetchOptions) (vaultResponse, error) {
+	headers := make(http.Header)
+	headers.Set("Accept", "application/json")
+	if token != "" {
+		headers.Set("X-Vault-Token", token)
+	}
+	if ns := f.client.vault.config.Namespace; cutil.NotEmpty(ns) {
+		headers.Set("X-Vault-Namespace", *ns)
+	}
+	requestOpts := FetchOptions{
+		Headers:    headers,
+		RetryCodes: opts.RetryCodes,
+	}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return vaultResponse{}, err
+		}
+		headers.Set("Content-Type", "application/json")
+		requestOpts.HTTPVerb = "POST"
+		requestOpts.body = encoded
+	}
+
+	resp, cancel, err := f.client.httpResponseWithHeader(requestOpts, u)
+	if cancel != nil {
+		defer cancel()
+	}
+	if err != nil {
+		return vaultResponse{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	var result vaultResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxVaultResponseSize)).Decode(&result)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return vaultResponse{}, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		if len(result.Errors) > 0 {
+			return vaultResponse{}, fmt.Errorf("%s: %s", http.StatusText(resp.StatusCode), strings.Join(result.Errors, "; "))
+		}
+		return vaultResponse{}, fmt.Errorf("%s", http.StatusText(resp.StatusCode))
+	case decodeErr != nil:
+		return vaultResponse{}, fmt.Errorf("parsing Vault response: %v", decodeErr)
+	}
+	return result, nil
+}
+
+// vaultEscapePath escapes each segment of a Vault API path.
+func vaultEscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
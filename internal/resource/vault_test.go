@@ -0,0 +1,158 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVault serves a KV version 1 engine at "kv", a KV version 2 engine
+// at "secret", and a Transit engine at "transit", requiring a token
+// obtained by logging in with AppRole.
+func newTestVault(t *testing.T) (*httptest.Server, *int32) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ns1", r.Header.Get("X-Vault-Namespace"))
+		reply := func(status int, v any) {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(v)
+		}
+		if r.URL.Path == "/v1/auth/approle/login" {
+			atomic.AddInt32(&logins, 1)
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["role_id"] != "node" || body["secret_id"] != "s3cret" {
+				reply(http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+				return
+			}
+			reply(http.StatusOK, map[string]any{"auth": map[string]any{"client_token": "hvs.token"}})
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "hvs.token" {
+			reply(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		switch {
+		case r.URL.Path == "/v1/kv/node":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"password": "$6$hash", "port": 8200}})
+		case r.URL.Path == "/v1/secret/data/node" && r.URL.Query().Get("version") == "1":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{
+				"data":     map[string]any{"key": "old"},
+				"metadata": map[string]any{"version": 1},
+			}})
+		case r.URL.Path == "/v1/secret/data/node":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{
+				"data":     map[string]any{"key": "new"},
+				"metadata": map[string]any{"version": 2},
+			}})
+		case r.URL.Path == "/v1/transit/decrypt/disk" && r.Method == "POST":
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["ciphertext"] != "vault:v1:abc+def" {
+				reply(http.StatusBadRequest, map[string]any{"errors": []string{"invalid ciphertext"}})
+				return
+			}
+			plaintext := base64.StdEncoding.EncodeToString([]byte("luks-key"))
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"plaintext": plaintext}})
+		default:
+			reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func TestFetchFromVault(t *testing.T) {
+	server, logins := newTestVault(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	logger := log.New(true)
+	f := Fetcher{Logger: &logger}
+	require.NoError(t, f.UpdateVault(types.Vault{
+		AuthMethod: util.StrToPtr("approle"),
+		Namespace:  util.StrToPtr("ns1"),
+		RoleID:     util.StrToPtr("node"),
+		SecretID:   types.Resource{Source: util.StrToPtr("data:,s3cret%0A")},
+	}))
+
+	tests := []struct {
+		source string
+		out    string
+		err    bool
+	}{
+		{source: "vault://" + host + "/kv/node?field=password", out: "$6$hash"},
+		{source: "vault://" + host + "/kv/node?field=port", out: "8200"},
+		{source: "vault://" + host + "/secret/data/node", out: "new"},
+		{source: "vault://" + host + "/secret/data/node?version=1", out: "old"},
+		{source: "vault://" + host + "/transit/decrypt/disk?ciphertext=vault:v1:abc+def", out: "luks-key"},
+		// multiple fields
+		{source: "vault://" + host + "/kv/node", err: true},
+		{source: "vault://" + host + "/kv/node?field=missing", err: true},
+		{source: "vault://" + host + "/kv/missing", err: true},
+		{source: "vault://" + host + "/transit/decrypt/disk?ciphertext=vault:v1:bad", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			u, err := url.Parse(test.source)
+			require.NoError(t, err)
+			data, err := f.FetchToBuffer(*u, FetchOptions{})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.out, string(data))
+		})
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(logins))
+}
+
+func TestFetchFromVaultLogin(t *testing.T) {
+	server, _ := newTestVault(t)
+	u, err := url.Parse("vault://" + strings.TrimPrefix(server.URL, "http://") + "/kv/node?field=password")
+	require.NoError(t, err)
+	logger := log.New(true)
+
+	f := Fetcher{Logger: &logger}
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.ErrorIs(t, err, ErrVaultNotConfigured)
+
+	require.NoError(t, f.UpdateVault(types.Vault{
+		AuthMethod: util.StrToPtr("approle"),
+		Namespace:  util.StrToPtr("ns1"),
+		RoleID:     util.StrToPtr("node"),
+		SecretID:   types.Resource{Source: util.StrToPtr("data:,wrong")},
+	}))
+	_, err = f.FetchToBuffer(*u, FetchOptions{})
+	assert.ErrorContains(t, err, "invalid role or secret ID")
+}
+
+func TestVaultBaseURL(t *testing.T) {
+	assert.Equal(t, "https://vault.example.com:8200/v1/", vaultBaseURL("vault.example.com:8200"))
+	assert.Equal(t, "http://127.0.0.1:8200/v1/", vaultBaseURL("127.0.0.1:8200"))
+}
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/log"
 )
 
 type State struct {
@@ -44,12 +45,30 @@ type State struct {
 	// Volume Key files generated during LUKS setup in disks stage, which
 	// need to be written out during files stage.
 	LuksPersistSecureKeyRepoFiles map[string]string `json:"luksPersistVolumeKeyFiles"`
+	// Variables available to file templates, collected by the fetch
+	// stages if the config uses templates.
+	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+	// Records of the stages which have run, including the one
+	// currently running.  Used when writing the result file in files
+	// stage.
+	Stages []StageReport `json:"stages"`
+}
+
+type StageReport struct {
+	Name            string  `json:"name"`
+	Started         string  `json:"started"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	// "running", "success", or "failure"
+	Outcome string `json:"outcome"`
+	*log.Recorder
 }
 
 type FetchedConfig struct {
 	Kind       string `json:"kind"`
 	Source     string `json:"source"`
 	Referenced bool   `json:"referenced"`
+	// Whether the config was decrypted, and so may contain secrets.
+	Encrypted bool `json:"encrypted,omitempty"`
 }
 
 func Load(path string) (State, error) {
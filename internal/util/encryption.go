@@ -0,0 +1,116 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/ignition/v2/internal/distro"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"golang.org/x/sys/unix"
+)
+
+// Decrypt decrypts data, an age file which may be ASCII-armored, with the
+// identity specified by e.
+func Decrypt(e types.Encryption, data []byte) ([]byte, error) {
+	identities, err := decryptionIdentities(e)
+	if err != nil {
+		return nil, fmt.Errorf("loading decryption key: %w", err)
+	}
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+	return plaintext, nil
+}
+
+// decryptionIdentities reads and parses the age identities from the key
+// source specified by e.
+func decryptionIdentities(e types.Encryption) ([]age.Identity, error) {
+	var key []byte
+	var err error
+	switch {
+	case cutil.NotEmpty(e.KeyFile):
+		key, err = os.ReadFile(*e.KeyFile)
+	case cutil.NotEmpty(e.SealedKeyFile):
+		key, err = unsealKeyFile(*e.SealedKeyFile)
+	case cutil.NotEmpty(e.KeyringKey):
+		key, err = readKeyringKey(*e.KeyringKey)
+	default:
+		return nil, fmt.Errorf("no decryption key specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return age.ParseIdentities(bytes.NewReader(key))
+}
+
+// unsealKeyFile decrypts a key sealed with clevis, e.g. to the TPM2.
+func unsealKeyFile(path string) ([]byte, error) {
+	sealed, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = sealed.Close()
+	}()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(distro.ClevisCmd(), "decrypt")
+	cmd.Stdin = sealed
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("unsealing %s: %v: %s", path, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// readKeyringKey reads the payload of the "user" key with the given
+// description from the session or user keyring.
+func readKeyringKey(description string) ([]byte, error) {
+	id, err := unix.KeyctlSearch(unix.KEY_SPEC_SESSION_KEYRING, "user", description, 0)
+	if err != nil {
+		id, err = unix.KeyctlSearch(unix.KEY_SPEC_USER_KEYRING, "user", description, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching kernel keyring for %q: %w", description, err)
+	}
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("reading key %q: %w", description, err)
+	}
+	buf := make([]byte, size)
+	size, err = unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	if err != nil {
+		return nil, fmt.Errorf("reading key %q: %w", description, err)
+	}
+	return buf[:size], nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cutil "github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ageEncrypt(t *testing.T, recipient age.Recipient, plaintext string, armored bool) []byte {
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var a io.WriteCloser
+	if armored {
+		a = armor.NewWriter(&buf)
+		dst = a
+	}
+	w, err := age.Encrypt(dst, recipient)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	if a != nil {
+		require.NoError(t, a.Close())
+	}
+	return buf.Bytes()
+}
+
+func TestDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "age.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("# created: today\n"+identity.String()+"\n"), 0600))
+	encryption := types.Encryption{KeyFile: cutil.StrToPtr(keyFile)}
+
+	for _, armored := range []bool{false, true} {
+		ciphertext := ageEncrypt(t, identity.Recipient(), "secret\n", armored)
+		plaintext, err := Decrypt(encryption, ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "secret\n", string(plaintext))
+	}
+
+	_, err = Decrypt(encryption, ageEncrypt(t, other.Recipient(), "secret\n", false))
+	assert.Error(t, err)
+
+	_, err = Decrypt(types.Encryption{KeyFile: cutil.StrToPtr(filepath.Join(t.TempDir(), "missing"))}, nil)
+	assert.Error(t, err)
+}
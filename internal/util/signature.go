@@ -0,0 +1,112 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoPublicKeys      = errors.New("no PEM-encoded public keys found")
+	ErrNoTrustedKeys     = errors.New("no trusted keys are configured to verify the signature")
+	ErrSignatureInvalid  = errors.New("signature verification failed")
+	ErrSignatureRequired = errors.New("config signatures are required but no signature was specified")
+	ErrUnsignedContent   = errors.New("config signatures are required, so the unsigned config may only contain the ignition section")
+)
+
+// ParsePublicKeys parses the PEM-encoded PKIX public keys in data. Ed25519,
+// ECDSA (P-256 and P-384), and RSA keys are supported.
+func ParsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		switch k := key.(type) {
+		case ed25519.PublicKey, *rsa.PublicKey:
+		case *ecdsa.PublicKey:
+			if k.Curve != elliptic.P256() && k.Curve != elliptic.P384() {
+				return nil, fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoPublicKeys
+	}
+	return keys, nil
+}
+
+// VerifySignature checks that sig is a detached signature of data made by
+// one of keys. The signature may be raw or base64-encoded, as produced by
+// e.g. "openssl pkeyutl" or "cosign sign-blob". ECDSA signatures are ASN.1
+// encoded, and ECDSA and RSA (PKCS #1 v1.5) signatures are over the SHA-256
+// digest of data, or the SHA-384 digest for P-384 keys.
+func VerifySignature(keys []crypto.PublicKey, data, sig []byte) error {
+	if len(keys) == 0 {
+		return ErrNoTrustedKeys
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig))); err == nil {
+		sig = decoded
+	}
+	for _, key := range keys {
+		if verifyWithKey(key, data, sig) {
+			return nil
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+func verifyWithKey(key crypto.PublicKey, data, sig []byte) bool {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P384() {
+			sum := sha512.Sum384(data)
+			return ecdsa.VerifyASN1(k, sum[:], sig)
+		}
+		sum := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(k, sum[:], sig)
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte(`{"ignition": {"version": "3.7.0-experimental"}}`)
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sum256 := sha256.Sum256(data)
+	sum384 := sha512.Sum384(data)
+	edSig := ed25519.Sign(edPriv, data)
+	p256Sig, err := ecdsa.SignASN1(rand.Reader, p256, sum256[:])
+	require.NoError(t, err)
+	p384Sig, err := ecdsa.SignASN1(rand.Reader, p384, sum384[:])
+	require.NoError(t, err)
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum256[:])
+	require.NoError(t, err)
+
+	// all of the keys in one PEM bundle
+	var bundle []byte
+	for _, key := range []crypto.PublicKey{edPub, &p256.PublicKey, &p384.PublicKey, &rsaKey.PublicKey} {
+		bundle = append(bundle, encodePublicKey(t, key)...)
+	}
+	keys, err := ParsePublicKeys(bundle)
+	require.NoError(t, err)
+	require.Len(t, keys, 4)
+
+	tests := []struct {
+		name string
+		keys []crypto.PublicKey
+		data []byte
+		sig  []byte
+		err  error
+	}{
+		{"ed25519", keys, data, edSig, nil},
+		{"ed25519 base64", keys, data, []byte(base64.StdEncoding.EncodeToString(edSig) + "\n"), nil},
+		{"ecdsa p256", keys, data, p256Sig, nil},
+		{"ecdsa p384", keys, data, p384Sig, nil},
+		{"rsa", keys, data, rsaSig, nil},
+		{"wrong key", keys[1:], data, edSig, ErrSignatureInvalid},
+		{"modified data", keys, append([]byte(" "), data...), edSig, ErrSignatureInvalid},
+		{"no keys", nil, data, edSig, ErrNoTrustedKeys},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.err, VerifySignature(test.keys, test.data, test.sig))
+		})
+	}
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	_, err := ParsePublicKeys([]byte("not a key"))
+	assert.Equal(t, ErrNoPublicKeys, err)
+
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	require.NoError(t, err)
+	_, err = ParsePublicKeys(encodePublicKey(t, &p224.PublicKey))
+	assert.Error(t, err)
+}
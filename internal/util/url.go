@@ -19,5 +19,10 @@ import (
 )
 
 func UrlNeedsNet(u url.URL) bool {
-	return u.Scheme != "data" && u.Scheme != ""
+	switch u.Scheme {
+	case "", "data", "file", "block":
+		return false
+	default:
+		return true
+	}
 }
@@ -16,6 +16,7 @@ package util
 
 import (
 	"crypto/sha256"
+	"crypto/sha3"
 	"crypto/sha512"
 	"encoding/hex"
 	"errors"
@@ -24,6 +25,8 @@ import (
 	"strings"
 
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+
+	"golang.org/x/crypto/blake2b"
 )
 
 var (
@@ -50,38 +53,97 @@ func HashParts(v types.Verification) (string, string, error) {
 		// The hash can be nil
 		return "", "", nil
 	}
-	parts := strings.SplitN(*v.Hash, "-", 2)
-	if len(parts) != 2 {
+	return splitHash(*v.Hash)
+}
+
+// splitHash splits a hash specifier into its function and sum. Function
+// names may contain dashes, but sums don't.
+func splitHash(s string) (string, string, error) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
 		return "", "", ErrHashMalformed
 	}
+	return s[:i], s[i+1:], nil
+}
+
+// Hashes returns the hash specifiers of the Verification: hash, followed by
+// hashes.
+func Hashes(verify types.Verification) []string {
+	var hashes []string
+	if verify.Hash != nil {
+		hashes = append(hashes, *verify.Hash)
+	}
+	return append(hashes, verify.Hashes...)
+}
 
-	return parts[0], parts[1], nil
+// TransportVerification returns a Verification of the fetched bytes of a
+// resource, before decompression, from its transportHash.
+func TransportVerification(verify types.Verification) types.Verification {
+	return types.Verification{Hash: verify.TransportHash}
+}
+
+func newHasher(function string) (hash.Hash, error) {
+	var h hash.Hash
+	switch function {
+	case "sha512":
+		h = sha512.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha256":
+		h = sha256.New()
+	case "sha3-256":
+		h = sha3.New256()
+	case "sha3-512":
+		h = sha3.New512()
+	case "blake2b-512":
+		var err error
+		if h, err = blake2b.New512(nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrHashUnrecognized
+	}
+	return namedHash{Hash: h, name: function}, nil
+}
+
+// HashName returns the function of a hasher returned by GetHasher, such as
+// "sha256", or "" for other hashers. For a hasher computing several
+// hashes, it's their functions joined with "+".
+func HashName(h hash.Hash) string {
+	switch h := h.(type) {
+	case namedHash:
+		return h.name
+	case multiHash:
+		var names []string
+		for _, hasher := range h {
+			name := HashName(hasher)
+			if name == "" {
+				return ""
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, "+")
+	default:
+		return ""
+	}
 }
 
 func AssertValid(verify types.Verification, data []byte) error {
-	if hash := verify.Hash; hash != nil {
-		hashFunc, hashSum, err := HashParts(verify)
+	for _, h := range Hashes(verify) {
+		hashFunc, hashSum, err := splitHash(h)
 		if err != nil {
 			return err
 		}
-
-		var sum []byte
-		switch hashFunc {
-		case "sha512":
-			rawSum := sha512.Sum512(data)
-			sum = rawSum[:]
-		case "sha256":
-			rawSum := sha256.Sum256(data)
-			sum = rawSum[:]
-		default:
-			return ErrHashUnrecognized
+		hasher, err := newHasher(hashFunc)
+		if err != nil {
+			return err
 		}
+		_, _ = hasher.Write(data)
 
-		encodedSum := make([]byte, hex.EncodedLen(len(sum)))
-		hex.Encode(encodedSum, sum)
-		if string(encodedSum) != hashSum {
+		encodedSum := hex.EncodeToString(hasher.Sum(nil))
+		if encodedSum != hashSum {
 			return ErrHashMismatch{
-				Calculated: string(encodedSum),
+				Calculated: encodedSum,
 				Expected:   hashSum,
 			}
 		}
@@ -90,22 +152,88 @@ func AssertValid(verify types.Verification, data []byte) error {
 	return nil
 }
 
+// GetHasher returns a hasher for the hashes of the Verification, or nil if
+// it has none. If there are several, the hasher computes all of them, and
+// its sum is their concatenation, as returned by ExpectedSum.
 func GetHasher(verify types.Verification) (hash.Hash, error) {
-	if verify.Hash == nil {
+	var hashers multiHash
+	for _, h := range Hashes(verify) {
+		function, _, err := splitHash(h)
+		if err != nil {
+			return nil, err
+		}
+		hasher, err := newHasher(function)
+		if err != nil {
+			return nil, err
+		}
+		hashers = append(hashers, hasher)
+	}
+
+	switch len(hashers) {
+	case 0:
 		return nil, nil
+	case 1:
+		return hashers[0], nil
+	default:
+		return hashers, nil
 	}
+}
 
-	function, _, err := HashParts(verify)
-	if err != nil {
-		return nil, err
+// ExpectedSum returns the sum expected from the hasher returned by
+// GetHasher, or nil if the Verification has no hashes.
+func ExpectedSum(verify types.Verification) ([]byte, error) {
+	var expected []byte
+	for _, h := range Hashes(verify) {
+		_, encodedSum, err := splitHash(h)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := hex.DecodeString(encodedSum)
+		if err != nil {
+			return nil, fmt.Errorf("parsing verification string %q: %v", h, err)
+		}
+		expected = append(expected, sum...)
 	}
+	return expected, nil
+}
 
-	switch function {
-	case "sha512":
-		return sha512.New(), nil
-	case "sha256":
-		return sha256.New(), nil
-	default:
-		return nil, ErrHashUnrecognized
+// namedHash is a hash along with the name of its function.
+type namedHash struct {
+	hash.Hash
+	name string
+}
+
+// multiHash computes several hashes of the same data.
+type multiHash []hash.Hash
+
+func (m multiHash) Write(p []byte) (int, error) {
+	for _, h := range m {
+		_, _ = h.Write(p)
+	}
+	return len(p), nil
+}
+
+func (m multiHash) Sum(b []byte) []byte {
+	for _, h := range m {
+		b = h.Sum(b)
+	}
+	return b
+}
+
+func (m multiHash) Reset() {
+	for _, h := range m {
+		h.Reset()
 	}
 }
+
+func (m multiHash) Size() int {
+	size := 0
+	for _, h := range m {
+		size += h.Size()
+	}
+	return size
+}
+
+func (m multiHash) BlockSize() int {
+	return m[0].BlockSize()
+}
@@ -15,9 +15,11 @@
 package util
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
+	cutil "github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 )
 
@@ -90,6 +92,41 @@ func TestAssertValid(t *testing.T) {
 				Expected:   "0519a9826023338828942b081814355d55301b9bc82042390f9afaf75cd3a707",
 			}},
 		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha384-59e1748777448c69de6b800d7a33bbfb9ff1b463e44354c3553bcdb9c666fa90125a3c79f90397bdf5f6a13de828684f"),
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha3-512-75d527c368f2efe848ecf6b073a36767800805e9eef2b1857d5f984f036eb6df891d75f72d9b154518c1cd58835286d1da9a38deba3de98b5a53e5ed78a84976"),
+					Hashes: []string{
+						"sha3-256-3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392",
+						"blake2b-512-e4cfa39a3d37be31c59609e807970799caa68a19bfaa15135f165085e01d41a65ba1e1b146aeb6bd0092b49eac214c103ccfa3a365954bbbe52f74a2b3620c94",
+					},
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash:   stringDeref("sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+					Hashes: []string{"sha3-256-0519a9826023338828942b081814355d55301b9bc82042390f9afaf75cd3a707"},
+				},
+				data: []byte("hello"),
+			},
+			out: out{err: ErrHashMismatch{
+				Calculated: "3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392",
+				Expected:   "0519a9826023338828942b081814355d55301b9bc82042390f9afaf75cd3a707",
+			}},
+		},
 	}
 
 	for i, test := range tests {
@@ -99,3 +136,36 @@ func TestAssertValid(t *testing.T) {
 		}
 	}
 }
+
+func TestGetHasher(t *testing.T) {
+	verification := types.Verification{
+		Hash: cutil.StrToPtr("sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+		Hashes: []string{
+			"sha3-256-3338be694f50c5f338814986cdf0686453a888b84f424d792af4b9202398f392",
+		},
+	}
+	hasher, err := GetHasher(verification)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, err := ExpectedSum(verification)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = hasher.Write([]byte("hel"))
+	_, _ = hasher.Write([]byte("lo"))
+	if !bytes.Equal(hasher.Sum(nil), expected) {
+		t.Errorf("bad sum: want %x, got %x", expected, hasher.Sum(nil))
+	}
+	if hasher.Size() != len(expected) {
+		t.Errorf("bad size: want %d, got %d", len(expected), hasher.Size())
+	}
+	if name := HashName(hasher); name != "sha256+sha3-256" {
+		t.Errorf("bad name: want %q, got %q", "sha256+sha3-256", name)
+	}
+
+	hasher, err = GetHasher(types.Verification{})
+	if hasher != nil || err != nil {
+		t.Errorf("expected no hasher, got %v, %v", hasher, err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+
+	"github.com/coreos/ignition/v2/tests/register"
+	"github.com/coreos/ignition/v2/tests/types"
+)
+
+func init() {
+	register.Register(register.NegativeTest, MergeBadlySignedConfig())
+	register.Register(register.NegativeTest, MergeUnsignedConfigRequired())
+	register.Register(register.NegativeTest, UnsignedContentRequired())
+}
+
+const (
+	// config writing "signed" to /foo/bar, and a signature of other data
+	signedConfig        = "data:;base64,eyJpZ25pdGlvbiI6eyJ2ZXJzaW9uIjoiMy4wLjAifSwic3RvcmFnZSI6eyJmaWxlcyI6W3sicGF0aCI6Ii9mb28vYmFyIiwiY29udGVudHMiOnsic291cmNlIjoiZGF0YTosc2lnbmVkJTBBIn19XX19"
+	mismatchedSignature = "data:;base64,Knou/bxRwnldUzesyqmsTVx1bH0e9KeB4NMoANLX6YG7FbARtggC0ZIfv3ZDOgbD+EMLX4kw2CuJaB54gRDmCw=="
+	signingPublicKey    = "data:,-----BEGIN%20PUBLIC%20KEY-----%0AMCowBQYDK2VwAyEA9Uz6Fy1+oSrhtciriwP+b27PahU22RY2eH2qpxe8QPo=%0A-----END%20PUBLIC%20KEY-----%0A"
+)
+
+// signingSystemFiles returns a base config trusting the signing key.
+func signingSystemFiles(required bool) []types.File {
+	return []types.File{
+		{
+			Node: types.Node{
+				Name:      "10-signatures.ign",
+				Directory: "base.d",
+			},
+			Contents: fmt.Sprintf(`{
+			  "ignition": {
+			    "version": "3.7.0-experimental",
+			    "security": {
+			      "signatures": {
+			        "required": %t,
+			        "trustedKeys": [{ "source": %q }]
+			      }
+			    }
+			  }
+			}`, required, signingPublicKey),
+		},
+	}
+}
+
+func MergeBadlySignedConfig() types.Test {
+	name := "security.signatures.merge.mismatch"
+	in := types.GetBaseDisk()
+	out := in
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": %q,
+	        "verification": { "signature": %q }
+	      }]
+	    }
+	  }
+	}`, signedConfig, mismatchedSignature)
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		SystemDirFiles:   signingSystemFiles(false),
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func MergeUnsignedConfigRequired() types.Test {
+	name := "security.signatures.required.unsigned"
+	in := types.GetBaseDisk()
+	out := in
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": %q
+	      }]
+	    }
+	  }
+	}`, signedConfig)
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		SystemDirFiles:   signingSystemFiles(true),
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func UnsignedContentRequired() types.Test {
+	name := "security.signatures.required.content"
+	in := types.GetBaseDisk()
+	out := in
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "files": [{
+	      "path": "/foo/bar",
+	      "contents": { "source": "data:,unsigned" }
+	    }]
+	  }
+	}`
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		SystemDirFiles:   signingSystemFiles(true),
+		ConfigMinVersion: configMinVersion,
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"github.com/coreos/ignition/v2/tests/register"
+	"github.com/coreos/ignition/v2/tests/types"
+)
+
+func init() {
+	register.Register(register.PositiveTest, ExtractCompressedTarArchive())
+	register.Register(register.PositiveTest, ExtractZipArchive())
+	register.Register(register.PositiveTest, ForceArchiveExtraction())
+}
+
+// ExtractCompressedTarArchive extracts a gzipped tarball containing
+// bin/hello (mode 0755) and a symlink hello -> bin/hello.
+func ExtractCompressedTarArchive() types.Test {
+	name := "archives.create.tar.gzip"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "archives": [{
+	      "path": "/opt/app",
+	      "contents": {
+	        "source": "data:;base64,H4sIAAAAAAACA+3UQQqDMBBA0Vl7ijlAoYlkzHkqLSiECtbS6ze4lC7cmCL5bzNZzO6T6cfnVQ7msmi2zmw7f7y7kNfVpID3a7nNqlKpPvcfHilN/+zvbdM/mmtFHf0Pt7a/6Gea070RVNm/wP3vQth//70zH0XbEsep8v8PAAAAAAAAAAAAAADO7wtWgxqtACgAAA==",
+	        "compression": "gzip"
+	      }
+	    }]
+	  }
+	}`
+	out[0].Partitions.AddDirectories("ROOT", []types.Directory{
+		{
+			Node: types.Node{
+				Directory: "opt/app",
+				Name:      "bin",
+			},
+			Mode: 0755,
+		},
+	})
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "opt/app/bin",
+				Name:      "hello",
+			},
+			Contents: "hello, world\n",
+			Mode:     0755,
+		},
+	})
+	out[0].Partitions.AddLinks("ROOT", []types.Link{
+		{
+			Node: types.Node{
+				Directory: "opt/app",
+				Name:      "hello",
+			},
+			Target: "bin/hello",
+		},
+	})
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+// ExtractZipArchive extracts a verified zip archive containing
+// etc/app.conf, overriding the file mode recorded in the archive.
+func ExtractZipArchive() types.Test {
+	name := "archives.create.zip"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "archives": [{
+	      "path": "/opt/app",
+	      "format": "zip",
+	      "fileMode": 384,
+	      "contents": {
+	        "source": "data:;base64,UEsDBBQAAAAAAAAAIVwAAAAAAAAAAAAAAAAEAAAAZXRjL1BLAwQUAAAAAAAAACFc7jluBAoAAAAKAAAADAAAAGV0Yy9hcHAuY29uZmtleT12YWx1ZQpQSwECFAMUAAAAAAAAACFcAAAAAAAAAAAAAAAABAAAAAAAAAAAABAA6EEAAAAAZXRjL1BLAQIUAxQAAAAAAAAAIVzuOW4ECgAAAAoAAAAMAAAAAAAAAAAAAACggSIAAABldGMvYXBwLmNvbmZQSwUGAAAAAAIAAgBsAAAAVgAAAAAA",
+	        "verification": { "hash": "sha512-056ca465e12a910b7db648d9ee0345358281c247dc9eae845cd3d9eeeb67ff76217ae688e7ef93b52062aee2f23d0805db5da330ff815293dd4d7f7082379b07" }
+	      }
+	    }]
+	  }
+	}`
+	out[0].Partitions.AddDirectories("ROOT", []types.Directory{
+		{
+			Node: types.Node{
+				Directory: "opt/app",
+				Name:      "etc",
+			},
+			Mode: 0750,
+		},
+	})
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "opt/app/etc",
+				Name:      "app.conf",
+			},
+			Contents: "key=value\n",
+			Mode:     0600,
+		},
+	})
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+// ForceArchiveExtraction replaces an existing directory with the contents
+// of the archive.
+func ForceArchiveExtraction() types.Test {
+	name := "archives.create.force"
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := `{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "archives": [{
+	      "path": "/opt/app",
+	      "overwrite": true,
+	      "contents": {
+	        "source": "data:;base64,H4sIAAAAAAACA+3UQQqDMBBA0Vl7ijlAoYlkzHkqLSiECtbS6ze4lC7cmCL5bzNZzO6T6cfnVQ7msmi2zmw7f7y7kNfVpID3a7nNqlKpPvcfHilN/+zvbdM/mmtFHf0Pt7a/6Gea070RVNm/wP3vQth//70zH0XbEsep8v8PAAAAAAAAAAAAAADO7wtWgxqtACgAAA==",
+	        "compression": "gzip"
+	      }
+	    }]
+	  }
+	}`
+	in[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "opt/app/bin",
+				Name:      "hello",
+			},
+			Contents: "old contents",
+		},
+	})
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Directory: "opt/app/bin",
+				Name:      "hello",
+			},
+			Contents: "hello, world\n",
+			Mode:     0755,
+		},
+	})
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
@@ -25,6 +25,9 @@ import (
 func init() {
 	register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTP())
 	register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTPCompressed())
+	for _, compression := range []string{"zstd", "xz", "bzip2"} {
+		register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTPCompressedWith(compression))
+	}
 	register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTPUsingHeaders())
 	register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTPUsingHeadersWithRedirect())
 	register.Register(register.PositiveTest, CreateFileFromRemoteContentsHTTPUsingOverwrittenHeaders())
@@ -105,6 +108,48 @@ func CreateFileFromRemoteContentsHTTPCompressed() types.Test {
 	}
 }
 
+// CreateFileFromRemoteContentsHTTPCompressedWith tests a compression type
+// added in 3.7.0-experimental; the server serves the contents compressed
+// with each of them at contents_compressed_<compression>.
+func CreateFileFromRemoteContentsHTTPCompressedWith(compression string) types.Test {
+	name := "files.create.http.compressed." + compression
+	in := types.GetBaseDisk()
+	out := types.GetBaseDisk()
+	config := strings.NewReplacer("HASH", servers.ContentsHash, "COMPRESSION", compression).Replace(`{
+	  "ignition": { "version": "$version" },
+	  "storage": {
+	    "files": [{
+	      "path": "/foo/bar",
+	      "contents": {
+	        "compression": "COMPRESSION",
+	        "source": "http://127.0.0.1:8080/contents_compressed_COMPRESSION",
+	        "verification": {
+	          "hash": "sha512-HASH"
+	        }
+	      }
+	    }]
+	  }
+	}`)
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Name:      "bar",
+				Directory: "foo",
+			},
+			Contents: "asdf\nfdsa",
+		},
+	})
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
 func CreateFileFromRemoteContentsHTTPUsingHeaders() types.Test {
 	name := "files.create.http.headers"
 	in := types.GetBaseDisk()
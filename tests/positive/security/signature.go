@@ -0,0 +1,125 @@
+// Copyright 2026 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"fmt"
+
+	"github.com/coreos/ignition/v2/tests/register"
+	"github.com/coreos/ignition/v2/tests/types"
+)
+
+func init() {
+	register.Register(register.PositiveTest, MergeSignedConfig())
+	register.Register(register.PositiveTest, ReplaceRequiredSignedConfig())
+}
+
+const (
+	// config writing "signed" to /foo/bar, and its ed25519 signature
+	signedConfig          = "data:;base64,eyJpZ25pdGlvbiI6eyJ2ZXJzaW9uIjoiMy4wLjAifSwic3RvcmFnZSI6eyJmaWxlcyI6W3sicGF0aCI6Ii9mb28vYmFyIiwiY29udGVudHMiOnsic291cmNlIjoiZGF0YTosc2lnbmVkJTBBIn19XX19"
+	signedConfigSignature = "data:;base64,PAzqjGHpa+ehy/wCbV/EoWU0fQov3/qWTQYdfqK0ZRRhZQTNC1CAEu76AaIG3d9jgRCdaMXZYPpd/jMKrAXRCg=="
+	signingPublicKey      = "data:,-----BEGIN%20PUBLIC%20KEY-----%0AMCowBQYDK2VwAyEA9Uz6Fy1+oSrhtciriwP+b27PahU22RY2eH2qpxe8QPo=%0A-----END%20PUBLIC%20KEY-----%0A"
+)
+
+// signingSystemFiles returns a base config trusting the signing key.
+func signingSystemFiles(required bool) []types.File {
+	return []types.File{
+		{
+			Node: types.Node{
+				Name:      "10-signatures.ign",
+				Directory: "base.d",
+			},
+			Contents: fmt.Sprintf(`{
+			  "ignition": {
+			    "version": "3.7.0-experimental",
+			    "security": {
+			      "signatures": {
+			        "required": %t,
+			        "trustedKeys": [{ "source": %q }]
+			      }
+			    }
+			  }
+			}`, required, signingPublicKey),
+		},
+	}
+}
+
+func signedConfigOutput() []types.Disk {
+	out := types.GetBaseDisk()
+	out[0].Partitions.AddFiles("ROOT", []types.File{
+		{
+			Node: types.Node{
+				Name:      "bar",
+				Directory: "foo",
+			},
+			Contents: "signed\n",
+		},
+	})
+	return out
+}
+
+func MergeSignedConfig() types.Test {
+	name := "security.signatures.merge"
+	in := types.GetBaseDisk()
+	out := signedConfigOutput()
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "merge": [{
+	        "source": %q,
+	        "verification": { "signature": %q }
+	      }]
+	    }
+	  }
+	}`, signedConfig, signedConfigSignature)
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		SystemDirFiles:   signingSystemFiles(false),
+		ConfigMinVersion: configMinVersion,
+	}
+}
+
+func ReplaceRequiredSignedConfig() types.Test {
+	name := "security.signatures.required.replace"
+	in := types.GetBaseDisk()
+	out := signedConfigOutput()
+	config := fmt.Sprintf(`{
+	  "ignition": {
+	    "version": "$version",
+	    "config": {
+	      "replace": {
+	        "source": %q,
+	        "verification": { "signature": %q }
+	      }
+	    }
+	  }
+	}`, signedConfig, signedConfigSignature)
+	configMinVersion := "3.7.0-experimental"
+
+	return types.Test{
+		Name:             name,
+		In:               in,
+		Out:              out,
+		Config:           config,
+		SystemDirFiles:   signingSystemFiles(true),
+		ConfigMinVersion: configMinVersion,
+	}
+}
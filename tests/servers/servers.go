@@ -28,7 +28,9 @@ import (
 	"time"
 
 	"github.com/coreos/ignition/v2/tests/fixtures"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pin/tftp"
+	"github.com/ulikunitz/xz"
 )
 
 var (
@@ -43,6 +45,8 @@ var (
 }`)
 	servedContents = []byte(`asdf
 fdsa`)
+	// servedContents compressed with bzip2
+	servedContentsBzip2 = []byte("BZh91AY&SY8\x9ai\xa5\x00\x00\x01A\x80\x00\x10%\x00\b\x00 \x00!\x83A\x9a\fiB\x1c]\xc9\x14\xe1B@\xe2i\xa6\x94")
 
 	// export these so tests don't have to hard-code them everywhere
 	configRawHash             = sha512.Sum512(servedConfig)
@@ -88,6 +92,49 @@ func compress(contents []byte) []byte {
 	return buf.Bytes()
 }
 
+func compressZstd(contents []byte) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := w.Write(contents); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func compressXz(contents []byte) []byte {
+	var buf bytes.Buffer
+	w, err := xz.NewWriter(&buf)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := w.Write(contents); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func (server *HTTPServer) ContentsCompressedZstd(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write(compressZstd(servedContents))
+}
+
+func (server *HTTPServer) ContentsCompressedXz(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write(compressXz(servedContents))
+}
+
+func (server *HTTPServer) ContentsCompressedBzip2(w http.ResponseWriter, r *http.Request) {
+	// the standard library can only decompress bzip2
+	_, _ = w.Write(servedContentsBzip2)
+}
+
 func (server *HTTPServer) ConfigCompressed(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(compress(servedConfig))
 }
@@ -248,6 +295,9 @@ type HTTPServer struct{}
 func (server *HTTPServer) Start() {
 	http.HandleFunc("/contents", server.Contents)
 	http.HandleFunc("/contents_compressed", server.ContentsCompressed)
+	http.HandleFunc("/contents_compressed_zstd", server.ContentsCompressedZstd)
+	http.HandleFunc("/contents_compressed_xz", server.ContentsCompressedXz)
+	http.HandleFunc("/contents_compressed_bzip2", server.ContentsCompressedBzip2)
 	http.HandleFunc("/contents_headers", server.ContentsHeaders)
 	http.HandleFunc("/contents_headers_redirect", server.ContentsRedirect)
 	http.HandleFunc("/contents_headers_redirected", server.ContentsRedirected)